@@ -0,0 +1,262 @@
+/*
+ * Copyright 2018-2025 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package labels
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	// GitAuthorsCommits is the number of commits, following first parents from HEAD, whose authors are counted when
+	// authors are read from git history.
+	GitAuthorsCommits = 100
+
+	// GitAuthorsLimit is the maximum number of authors read from git history.
+	GitAuthorsLimit = 5
+
+	// authorsProseWords is the number of words above which a line of an authors file without an email address is
+	// taken to be prose rather than a name.
+	authorsProseWords = 8
+)
+
+// CodeownersPaths are the paths of the CODEOWNERS file in an application, in the order GitHub looks for them. Only
+// the first found is read.
+var CodeownersPaths = []string{".github/CODEOWNERS", "CODEOWNERS", "docs/CODEOWNERS"}
+
+// AuthorsFiles are the names, matched case-insensitively, of the files listing the authors or maintainers of an
+// application, in order of precedence.
+var AuthorsFiles = []string{"AUTHORS", "AUTHORS.md", "AUTHORS.txt", "MAINTAINERS", "MAINTAINERS.md", "MAINTAINERS.txt"}
+
+// codeownersCatchAll are the CODEOWNERS patterns that match every file.
+var codeownersCatchAll = map[string]bool{"*": true, "**": true, "/**": true, "**/*": true, "/**/*": true}
+
+var (
+	authorEmail     = regexp.MustCompile(`^(.*?)\s*<([^<>\s]+@[^<>\s]+)>(.*)$`)
+	authorsListItem = regexp.MustCompile(`^(?:[-*+]|\d{1,9}[.)])\s+`)
+)
+
+// ReadAuthors returns the authors of an application as a comma-separated list and the origin they were read from,
+// or an empty string if none can be found. The owners of every file in the first CODEOWNERS file are used, falling
+// back to the first AUTHORS or MAINTAINERS file, and then to the most frequent authors in git history.
+func ReadAuthors(applicationPath string) (string, Origin, error) {
+	for _, p := range CodeownersPaths {
+		b, err := os.ReadFile(filepath.Join(applicationPath, filepath.FromSlash(p)))
+		if os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return "", Origin{}, fmt.Errorf("unable to read %s\n%w", p, err)
+		}
+
+		if a := FormatAuthors(ParseCodeowners(string(b))); a != "" {
+			return a, Origin{Kind: OriginInferred, Name: p}, nil
+		}
+		break
+	}
+
+	name, ok, err := findFileFold(applicationPath, AuthorsFiles)
+	if err != nil {
+		return "", Origin{}, err
+	} else if ok {
+		b, err := os.ReadFile(filepath.Join(applicationPath, name))
+		if err != nil {
+			return "", Origin{}, fmt.Errorf("unable to read %s\n%w", name, err)
+		}
+
+		if a := FormatAuthors(ParseAuthorsFile(string(b))); a != "" {
+			return a, Origin{Kind: OriginInferred, Name: name}, nil
+		}
+	}
+
+	g, ok, err := NewGitRepository(applicationPath)
+	if err != nil {
+		return "", Origin{}, fmt.Errorf("unable to read git repository\n%w", err)
+	} else if !ok {
+		return "", Origin{}, nil
+	}
+
+	m, err := g.Metadata()
+	if err != nil {
+		return "", Origin{}, fmt.Errorf("unable to read git metadata\n%w", err)
+	} else if m.Revision == "" {
+		return "", Origin{}, nil
+	}
+
+	authors, err := g.Authors(m.Revision)
+	if err != nil {
+		return "", Origin{}, fmt.Errorf("unable to read authors from git history\n%w", err)
+	}
+
+	if a := FormatAuthors(authors); a != "" {
+		return a, Origin{Kind: OriginGit, Name: ".git"}, nil
+	}
+	return "", Origin{}, nil
+}
+
+// ParseCodeowners returns the owners of the last catch-all rule (*, ** or /**) of a CODEOWNERS file, who own every
+// file not matched by a more specific rule. Comments and GitLab section headers are ignored.
+func ParseCodeowners(text string) []string {
+	var owners []string
+
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "[") || strings.HasPrefix(line, "^[") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if !codeownersCatchAll[fields[0]] {
+			continue
+		}
+
+		owners = nil
+		for _, f := range fields[1:] {
+			if strings.HasPrefix(f, "#") {
+				break
+			}
+			owners = append(owners, f)
+		}
+	}
+
+	return owners
+}
+
+// ParseAuthorsFile returns the people listed one per line in an AUTHORS or MAINTAINERS file, in text or Markdown.
+// Comments, headings, list markers and link targets are removed, and lines that introduce a list by ending with ':'
+// or that are prose of more than eight words without an email address are ignored.
+func ParseAuthorsFile(text string) []string {
+	var authors []string
+
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "<!--") ||
+			strings.HasPrefix(line, "|") || strings.HasSuffix(line, ":") {
+			continue
+		}
+
+		line = authorsListItem.ReplaceAllString(line, "")
+		line = markdownImage.ReplaceAllString(line, "")
+		line = markdownLink.ReplaceAllString(line, "$1")
+		line = markdownEmphasis.ReplaceAllString(line, "")
+
+		if !strings.Contains(line, "@") && len(strings.Fields(line)) > authorsProseWords {
+			continue
+		}
+
+		if line = strings.TrimSpace(line); line != "" {
+			authors = append(authors, line)
+		}
+	}
+
+	return authors
+}
+
+// FormatAuthors normalizes authors to the "Name <email>" form with whitespace collapsed, and joins them with ", ",
+// leaving out those with the same email address, or the same name if they have none, as an earlier author.
+func FormatAuthors(authors []string) string {
+	var (
+		formatted []string
+		seen      = make(map[string]bool)
+	)
+
+	for _, a := range authors {
+		a = strings.Join(strings.Fields(a), " ")
+		key := strings.ToLower(a)
+
+		if m := authorEmail.FindStringSubmatch(a); m != nil {
+			a = strings.TrimSpace(person(m[1], m[2]) + " " + strings.TrimSpace(m[3]))
+			key = strings.ToLower(m[2])
+		}
+
+		if a == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		formatted = append(formatted, a)
+	}
+
+	return strings.Join(formatted, ", ")
+}
+
+// Authors returns up to GitAuthorsLimit of the authors of the last GitAuthorsCommits commits from a commit,
+// following first parents, with the most commits first. Authors with the same email address are counted together,
+// bots are left out and history is read up to the boundary of a shallow clone.
+func (g GitRepository) Authors(sha string) ([]string, error) {
+	shallow := make(map[string]bool)
+	if b, err := os.ReadFile(filepath.Join(g.CommonDir, "shallow")); err == nil {
+		for _, s := range strings.Fields(string(b)) {
+			shallow[s] = true
+		}
+	} else if !os.IsNotExist(err) {
+		return nil, fmt.Errorf("unable to read shallow\n%w", err)
+	}
+
+	var (
+		authors []string
+		counts  = make(map[string]int)
+		index   = make(map[string]int)
+	)
+	for i := 0; i < GitAuthorsCommits && sha != ""; i++ {
+		c, err := g.ReadCommit(sha)
+		if err != nil {
+			return nil, err
+		}
+
+		if c.Author != "" && !strings.Contains(strings.ToLower(c.Author), "[bot]") {
+			key := strings.ToLower(c.Author)
+			if m := authorEmail.FindStringSubmatch(c.Author); m != nil {
+				key = strings.ToLower(m[2])
+			}
+
+			if _, ok := index[key]; !ok {
+				index[key] = len(authors)
+				authors = append(authors, c.Author)
+			}
+			counts[key]++
+		}
+
+		if shallow[sha] || len(c.Parents) == 0 {
+			break
+		}
+		sha = c.Parents[0]
+	}
+
+	keys := make([]string, 0, len(index))
+	for k := range index {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if counts[keys[i]] != counts[keys[j]] {
+			return counts[keys[i]] > counts[keys[j]]
+		}
+		return index[keys[i]] < index[keys[j]]
+	})
+
+	var ranked []string
+	for _, k := range keys {
+		if len(ranked) == GitAuthorsLimit {
+			break
+		}
+		ranked = append(ranked, authors[index[k]])
+	}
+
+	return ranked, nil
+}
@@ -0,0 +1,178 @@
+/*
+ * Copyright 2018-2025 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package labels_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/BurntSushi/toml"
+	. "github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+
+	"github.com/paketo-buildpacks/image-labels/v4/labels"
+)
+
+func testAuthors(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+	)
+
+	context("fixtures", func() {
+		// Each directory in testdata/authors holds an application in app and the authors expected from it, and the
+		// path of the file they are read from, in expected.toml.
+		fixtures, err := os.ReadDir(filepath.Join("testdata", "authors"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, f := range fixtures {
+			name := f.Name()
+
+			it(fmt.Sprintf("reads the authors of %s", name), func() {
+				var expected struct {
+					Authors string `toml:"authors"`
+					Origin  string `toml:"origin"`
+				}
+				_, err := toml.DecodeFile(filepath.Join("testdata", "authors", name, "expected.toml"), &expected)
+				Expect(err).NotTo(HaveOccurred())
+
+				authors, origin, err := labels.ReadAuthors(filepath.Join("testdata", "authors", name, "app"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(authors).To(Equal(expected.Authors))
+				Expect(origin.Name).To(Equal(expected.Origin))
+			})
+		}
+	})
+
+	context("git history", func() {
+		var dir string
+
+		// commit writes a commit by an author with a parent, if not empty, and returns its name.
+		commit := func(author string, parent string) string {
+			var b strings.Builder
+			b.WriteString("tree 4b825dc642cb6eb9a060e54bf8d69288fbee4904\n")
+			if parent != "" {
+				fmt.Fprintf(&b, "parent %s\n", parent)
+			}
+			fmt.Fprintf(&b, "author %[1]s 1700000000 +0000\ncommitter %[1]s 1700000000 +0000\n\nChange\n", author)
+
+			return writeLooseObject(t, filepath.Join(dir, ".git"), "commit", []byte(b.String()))
+		}
+
+		it.Before(func() {
+			dir = t.TempDir()
+		})
+
+		it("falls back to the authors with the most commits", func() {
+			head := ""
+			for _, a := range []string{
+				"Jane Doe <jane@example.com>",
+				"John Doe <john@example.com>",
+				"dependabot[bot] <49699333+dependabot[bot]@users.noreply.github.com>",
+				"Jane <JANE@example.com>",
+				"dependabot[bot] <49699333+dependabot[bot]@users.noreply.github.com>",
+				"Ann Smith <ann@example.com>",
+			} {
+				head = commit(a, head)
+			}
+			writeFiles(t, dir, map[string]string{
+				".git/HEAD":            "ref: refs/heads/main\n",
+				".git/refs/heads/main": head + "\n",
+			})
+
+			authors, origin, err := labels.ReadAuthors(dir)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(authors).To(Equal("Jane <JANE@example.com>, Ann Smith <ann@example.com>, John Doe <john@example.com>"))
+			Expect(origin).To(Equal(labels.Origin{Kind: labels.OriginGit, Name: ".git"}))
+		})
+
+		it("stops at the boundary of a shallow clone", func() {
+			first := commit("Jane Doe <jane@example.com>", "")
+			second := commit("John Doe <john@example.com>", first)
+			writeFiles(t, dir, map[string]string{".git/shallow": second + "\n"})
+
+			g, _, err := labels.NewGitRepository(dir)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(g.Authors(second)).To(Equal([]string{"John Doe <john@example.com>"}))
+		})
+
+		it("reads at most the last commits", func() {
+			head := commit("Jane Doe <jane@example.com>", "")
+			for range labels.GitAuthorsCommits {
+				head = commit("John Doe <john@example.com>", head)
+			}
+
+			g, _, err := labels.NewGitRepository(dir)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(g.Authors(head)).To(Equal([]string{"John Doe <john@example.com>"}))
+		})
+
+		it("returns at most the authors with the most commits", func() {
+			head := ""
+			for i := range labels.GitAuthorsLimit + 2 {
+				head = commit(fmt.Sprintf("Author %[1]d <author-%[1]d@example.com>", i), head)
+			}
+
+			g, _, err := labels.NewGitRepository(dir)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(g.Authors(head)).To(HaveLen(labels.GitAuthorsLimit))
+		})
+
+		it("prefers an authors file", func() {
+			head := commit("Jane Doe <jane@example.com>", "")
+			writeFiles(t, dir, map[string]string{
+				".git/HEAD": head + "\n",
+				"AUTHORS":   "John Doe <john@example.com>\n",
+			})
+
+			authors, origin, err := labels.ReadAuthors(dir)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(authors).To(Equal("John Doe <john@example.com>"))
+			Expect(origin).To(Equal(labels.Origin{Kind: labels.OriginInferred, Name: "AUTHORS"}))
+		})
+	})
+
+	context("ParseCodeowners", func() {
+		it("returns nothing when the last catch-all rule has no owners", func() {
+			Expect(labels.ParseCodeowners("* @example/maintainers\n*\n")).To(BeEmpty())
+		})
+
+		it("returns nothing without a catch-all rule", func() {
+			Expect(labels.ParseCodeowners("/docs/ @example/docs\n")).To(BeEmpty())
+		})
+	})
+
+	context("FormatAuthors", func() {
+		it("normalizes and joins authors", func() {
+			Expect(labels.FormatAuthors([]string{
+				"  Jane   Doe<jane@example.com>  ",
+				"@example/platform",
+				"<john@example.com>",
+				"Jane <JANE@EXAMPLE.COM>",
+				"@example/platform",
+				"",
+			})).To(Equal("Jane Doe <jane@example.com>, @example/platform, john@example.com"))
+		})
+	})
+}
@@ -17,9 +17,13 @@
 package labels
 
 import (
+	"errors"
 	"fmt"
-	"sort"
+	"os"
+	"strconv"
 	"strings"
+	"time"
+	"unicode/utf8"
 
 	"github.com/buildpacks/libcnb/v2"
 	"github.com/paketo-buildpacks/libpak/v2"
@@ -41,195 +45,429 @@ func NewBuild(logger log.Logger) libcnb.BuildFunc {
 			return libcnb.BuildResult{}, fmt.Errorf("unable to create configuration resolver\n%w", err)
 		}
 
-		for k, v := range Labels {
+		conflicts, err := ResolveConflictPolicy(cr)
+		if err != nil {
+			return libcnb.BuildResult{}, err
+		}
+
+		policies, err := ResolvePolicies(context.ApplicationPath, context.Buildpack.Metadata, cr)
+		if err != nil {
+			return libcnb.BuildResult{}, fmt.Errorf("unable to resolve label policy\n%w", err)
+		}
+
+		labels := NewCollector(conflicts, logger)
+
+		dockerfile, ok, err := FindDockerfile(context.ApplicationPath, cr)
+		if err != nil {
+			return libcnb.BuildResult{}, fmt.Errorf("unable to find Dockerfile\n%w", err)
+		} else if ok {
+			logger.Bodyf("Reading labels from %s", dockerfile)
+			d, err := ReadDockerfile(dockerfile)
+			if err != nil {
+				return libcnb.BuildResult{}, fmt.Errorf("unable to read Dockerfile\n%w", err)
+			}
+
+			for _, l := range d {
+				if l.Unresolved != "" {
+					logger.Bodyf("Ignoring label %s from %s:%d, $%s is only known when the image is built", l.Key, dockerfile, l.Line, l.Unresolved)
+					continue
+				}
+
+				if err := labels.Add(l.Key, l.Value, Origin{Kind: OriginFile, Name: dockerfile, Line: l.Line}); err != nil {
+					return libcnb.BuildResult{}, err
+				}
+			}
+		}
+
+		file, ok, err := FindLabelsFile(context.ApplicationPath, cr)
+		if err != nil {
+			return libcnb.BuildResult{}, fmt.Errorf("unable to find labels file\n%w", err)
+		} else if ok {
+			logger.Bodyf("Reading labels from %s", file)
+			m, lines, err := ReadLabelsFileLines(file)
+			if err != nil {
+				return libcnb.BuildResult{}, fmt.Errorf("unable to read labels file\n%w", err)
+			}
+
+			for _, k := range sortedKeys(m) {
+				if err := labels.Add(k, m[k], Origin{Kind: OriginFile, Name: file, Line: lines[k]}); err != nil {
+					return libcnb.BuildResult{}, err
+				}
+			}
+		}
+
+		for _, k := range sortedKeys(Labels) {
 			if s, ok := cr.Resolve(k); ok {
-				result.Labels = append(result.Labels, libcnb.Label{Key: v, Value: s})
+				v := Labels[k]
+
+				s, err := Expand(s, os.LookupEnv)
+				if err != nil {
+					return libcnb.BuildResult{}, fmt.Errorf("unable to expand $%s for label %s\n%w", k, v, err)
+				}
+
+				if k == "BP_OCI_CREATED" && strings.EqualFold(strings.TrimSpace(s), CreatedAuto) {
+					created, source, err := ResolveCreated(context.ApplicationPath, cr, time.Now())
+					if err != nil {
+						return libcnb.BuildResult{}, fmt.Errorf("unable to resolve %s\n%w", v, err)
+					}
+					logger.Bodyf("Using %s=%s from %s", v, created, source)
+					s = created
+				}
+
+				if err := labels.Add(v, s, Origin{Kind: OriginEnvironment, Name: k}); err != nil {
+					return libcnb.BuildResult{}, err
+				}
 			}
 		}
 
 		if s, ok := cr.Resolve("BP_IMAGE_LABELS"); ok {
-			words, err := ParseLabels(s)
+			l, err := ParseLabelList(s)
 			if err != nil {
+				var pe *ParseError
+				if errors.As(err, &pe) {
+					logger.Body(pe.Format())
+				}
 				return libcnb.BuildResult{}, fmt.Errorf("unable to parse %s\n%w", s, err)
 			}
 
-			keys := []string{}
-			for k := range words {
-				keys = append(keys, k)
+			for _, l := range l {
+				if err := labels.Add(l.Key, l.Value, Origin{Kind: OriginEnvironment, Name: "BP_IMAGE_LABELS"}); err != nil {
+					return libcnb.BuildResult{}, err
+				}
+			}
+		}
+
+		env, err := EnvironmentLabels(os.Environ())
+		if err != nil {
+			return libcnb.BuildResult{}, fmt.Errorf("unable to read label environment variables\n%w", err)
+		}
+
+		for _, l := range env {
+			v, err := Expand(l.Value, os.LookupEnv)
+			if err != nil {
+				return libcnb.BuildResult{}, fmt.Errorf("unable to expand $%s for label %s\n%w", l.Variable, l.Key, err)
 			}
-			sort.Strings(keys)
 
-			for _, key := range keys {
-				result.Labels = append(result.Labels, libcnb.Label{Key: key, Value: words[key]})
+			if err := labels.Add(l.Key, v, Origin{Kind: OriginEnvironment, Name: l.Variable}); err != nil {
+				return libcnb.BuildResult{}, err
 			}
 		}
 
-		return result, nil
-	}
-}
+		planned, err := PlanLabels(context.Plan)
+		if err != nil {
+			return libcnb.BuildResult{}, fmt.Errorf("unable to read build plan labels\n%w", err)
+		}
 
-// ReadToNext rune in string consuming the character
-//
-// It returns a string of read characters, a string of the remaining characters
-// and the specific rune that was read.
-func ReadToNext(buf string, chars string) (string, string, rune) {
-	i := strings.IndexAny(buf, chars)
+		contributors := make(map[string]int)
+		for _, l := range planned {
+			if e, ok := contributors[l.Key]; ok {
+				logger.Bodyf("Ignoring %s=%s from build plan entry %d, it is contributed by build plan entry %d", l.Key, l.Value, l.Entry, e)
+				continue
+			}
 
-	if i > 0 && i < len(buf) {
-		return buf[0:i], buf[i+1:], rune(buf[i])
-	}
-	if i > 0 && i == len(buf) {
-		return buf[0 : i-1], "", rune(buf[i])
-	}
-	if i == 0 && i < len(buf) {
-		return "", buf[i+1:], rune(buf[i])
-	}
-	return buf, "", rune(0)
-}
+			if labels.Has(l.Key) {
+				logger.Bodyf("Ignoring %s=%s from build plan entry %d, it is configured by the user", l.Key, l.Value, l.Entry)
+				continue
+			}
 
-// ReadKey from the string
-//
-// A key is either all words before the equals sign, or a single or
-// double quoted word group, again before an equals sign.
-//
-// If key is quoted and there are characters between the ending quote
-// and the equals sign, those are discarded.
-//
-// It returns the key, a string with the remainder of the characters
-// or an error.
-func ReadKey(buf string) (string, string, error) {
-	item := ""
-	needClosingQuote := false
-	key, rest, ch := ReadToNext(buf, `"'=`)
-	for {
-		// unescape, escaped single and double quotes
-		if (ch == '"' || ch == '\'') && strings.HasSuffix(key, `\`) {
-			key = fmt.Sprintf("%s%c", strings.TrimSuffix(key, `\`), ch)
-			ch = '\\'
+			logger.Bodyf("Using %s=%s from build plan entry %d", l.Key, l.Value, l.Entry)
+			if err := labels.Add(l.Key, l.Value, Origin{Kind: OriginBuildPlan, Name: strconv.Itoa(l.Entry)}); err != nil {
+				return libcnb.BuildResult{}, err
+			}
+			contributors[l.Key] = l.Entry
+		}
+
+		if cr.ResolveBool("BP_IMAGE_LABELS_CI") {
+			if p, ci, ok := CILabels(os.Getenv); !ok {
+				logger.Body("No CI provider detected")
+			} else {
+				for _, l := range ci {
+					if labels.Has(l.Key) {
+						continue
+					}
+
+					logger.Bodyf("Using %s=%s from %s", l.Key, l.Value, p.Name)
+					if err := labels.Add(l.Key, l.Value, Origin{Kind: OriginCI, Name: p.Name}); err != nil {
+						return libcnb.BuildResult{}, err
+					}
+				}
+			}
 		}
 
-		item += key
+		if cr.ResolveBool("BP_IMAGE_LABELS_GIT") {
+			g, err := gitLabels(context.ApplicationPath, logger)
+			if err != nil {
+				return libcnb.BuildResult{}, fmt.Errorf("unable to read git metadata\n%w", err)
+			}
 
-		// end quote
-		if (ch == '"' || ch == '\'') && needClosingQuote {
-			invalid, rest, _ := ReadToNext(rest, `=`)
-			if len(strings.TrimSpace(invalid)) > 0 {
-				return "", rest, fmt.Errorf("unable to have characters after a trailing quote")
+			for _, l := range g {
+				if labels.Has(l.Key) {
+					continue
+				}
+				if err := labels.Add(l.Key, l.Value, Origin{Kind: OriginGit, Name: ".git"}); err != nil {
+					return libcnb.BuildResult{}, err
+				}
 			}
-			return item, rest, nil
 		}
 
-		// beginning quote
-		if (ch == '"' || ch == '\'') && !needClosingQuote {
-			needClosingQuote = true
+		if cr.ResolveBool("BP_IMAGE_LABELS_INFER") {
+			inferred, err := InferLabels(context.ApplicationPath, logger)
+			if err != nil {
+				return libcnb.BuildResult{}, fmt.Errorf("unable to infer labels\n%w", err)
+			}
+
+			for _, l := range inferred {
+				if labels.Has(l.Key) {
+					continue
+				}
+				logger.Bodyf("Inferred %s=%s from %s", l.Key, l.Value, l.Source)
+				if err := labels.Add(l.Key, l.Value, Origin{Kind: OriginInferred, Name: l.Source}); err != nil {
+					return libcnb.BuildResult{}, err
+				}
+			}
 		}
 
-		// end of key or no more data
-		if (ch == '=' || rest == "") && needClosingQuote {
-			return "", rest, fmt.Errorf("unable to find a closing quote")
-		} else if ch == '=' || rest == "" {
-			return item, rest, nil
+		if cr.ResolveBool("BP_IMAGE_LABELS_README") {
+			length, err := ResolveReadmeLength(cr)
+			if err != nil {
+				return libcnb.BuildResult{}, err
+			}
+
+			readme, name, err := readmeLabels(context.ApplicationPath, length, logger)
+			if err != nil {
+				return libcnb.BuildResult{}, fmt.Errorf("unable to read README\n%w", err)
+			}
+
+			for _, l := range readme {
+				if labels.Has(l.Key) {
+					continue
+				}
+				logger.Bodyf("Inferred %s=%s from %s", l.Key, l.Value, name)
+				if err := labels.Add(l.Key, l.Value, Origin{Kind: OriginInferred, Name: name}); err != nil {
+					return libcnb.BuildResult{}, err
+				}
+			}
 		}
 
-		key, rest, ch = ReadToNext(rest, `"'=`)
-	}
-}
+		if key := Labels["BP_OCI_AUTHORS"]; cr.ResolveBool("BP_IMAGE_LABELS_AUTHORS") && !labels.Has(key) {
+			authors, origin, err := ReadAuthors(context.ApplicationPath)
+			if err != nil {
+				return libcnb.BuildResult{}, fmt.Errorf("unable to read authors\n%w", err)
+			}
 
-// ReadValue from the string
-//
-// A value is either all words up to the first space character, or a
-// single or double quoted word group, again before the first space.
-//
-// If value is quoted and there are characters between the ending quote
-// and the equals sign, those are discarded.
-//
-// It returns the value, a string with the remainder of the characters
-// or an error.
-func ReadValue(buf string) (string, string, error) {
-	item := ""
-	needClosingQuote := false
-	value, rest, ch := ReadToNext(buf, `"' `)
-	for {
-		// unescape, escaped single and double quotes
-		if (ch == '"' || ch == '\'') && strings.HasSuffix(value, `\`) {
-			value = fmt.Sprintf("%s%c", strings.TrimSuffix(value, `\`), ch)
-			ch = '\\'
+			if authors == "" {
+				logger.Body("No authors found in application")
+			} else {
+				logger.Bodyf("Inferred %s=%s from %s", key, authors, origin)
+				if err := labels.Add(key, authors, origin); err != nil {
+					return libcnb.BuildResult{}, err
+				}
+			}
+		}
+
+		if key := Labels["BP_OCI_LICENSES"]; cr.ResolveBool("BP_IMAGE_LABELS_LICENSE") && !labels.Has(key) {
+			license, files, err := licenseLabel(context.ApplicationPath, logger)
+			if err != nil {
+				return libcnb.BuildResult{}, fmt.Errorf("unable to identify license\n%w", err)
+			}
+
+			if license != "" {
+				source := strings.Join(files, ", ")
+				logger.Bodyf("Inferred %s=%s from %s", key, license, source)
+				if err := labels.Add(key, license, Origin{Kind: OriginInferred, Name: source}); err != nil {
+					return libcnb.BuildResult{}, err
+				}
+			}
 		}
 
-		item += value
+		defaults, err := DefaultLabels(context.Buildpack.Metadata)
+		if err != nil {
+			return libcnb.BuildResult{}, fmt.Errorf("unable to read default labels\n%w", err)
+		}
+
+		for _, l := range defaults {
+			if labels.Has(l.Key) {
+				continue
+			}
+
+			v, err := Expand(l.Value, os.LookupEnv)
+			if err != nil {
+				return libcnb.BuildResult{}, fmt.Errorf("unable to expand default label %s\n%w", l.Key, err)
+			}
 
-		// end quote
-		if (ch == '"' || ch == '\'') && needClosingQuote {
-			invalid, rest, _ := ReadToNext(rest, ` `)
-			if len(strings.TrimSpace(invalid)) > 0 {
-				return "", rest, fmt.Errorf("unable to have characters after a trailing quote")
+			logger.Bodyf("Using default %s=%s", l.Key, v)
+			if err := labels.Add(l.Key, v, Origin{Kind: OriginDefault, Name: "buildpack.toml"}); err != nil {
+				return libcnb.BuildResult{}, err
 			}
-			return item, rest, nil
 		}
 
-		// beginning quote
-		if (ch == '"' || ch == '\'') && !needClosingQuote {
-			needClosingQuote = true
+		result.Labels = labels.Labels()
+
+		if err := validateLabels(result.Labels, cr.ResolveBool("BP_IMAGE_LABELS_STRICT"), logger); err != nil {
+			return libcnb.BuildResult{}, err
 		}
 
-		// successful end of value
-		if ch == ' ' && !needClosingQuote {
-			return item, rest, nil
+		var violations []string
+		for _, p := range policies {
+			logger.Bodyf("Checking labels against the label policy from %s", p.Source)
+			violations = append(violations, p.Violations(result.Labels)...)
+		}
+		if len(violations) > 0 {
+			return libcnb.BuildResult{}, fmt.Errorf("labels do not satisfy the label policy\n%s", strings.Join(violations, "\n"))
 		}
 
-		// embedded space
-		if ch == ' ' && needClosingQuote {
-			item += " "
+		if len(result.Labels) > 0 {
+			provenance := labels.Provenance()
+			LogProvenance(provenance, logger)
+
+			layer, err := ContributeProvenance(context.Layers, provenance)
+			if err != nil {
+				return libcnb.BuildResult{}, fmt.Errorf("unable to contribute label provenance\n%w", err)
+			}
+			result.Layers = append(result.Layers, layer)
 		}
 
-		// end of data buffer
-		if rest == "" && needClosingQuote {
-			return "", rest, fmt.Errorf("unable to find a closing quote")
-		} else if rest == "" {
-			return item, rest, nil
+		if cr.ResolveBool("BP_IMAGE_LABELS_RUNTIME") {
+			layer, err := ContributeRuntimeLabels(context.Layers, result.Labels, cr.ResolveBool("BP_IMAGE_LABELS_RUNTIME_ENV"), logger)
+			if err != nil {
+				return libcnb.BuildResult{}, fmt.Errorf("unable to contribute runtime labels\n%w", err)
+			}
+			result.Layers = append(result.Layers, layer)
 		}
 
-		value, rest, ch = ReadToNext(rest, `"' `)
+		return result, nil
 	}
 }
 
-func ParseLabels(rest string) (map[string]string, error) {
-	m := make(map[string]string)
+// ReadToNext rune in string consuming the character
+//
+// It returns a string of read characters, a string of the remaining characters
+// and the specific rune that was read, which is 0 if none of the characters
+// were found.
+func ReadToNext(buf string, chars string) (string, string, rune) {
+	i := strings.IndexAny(buf, chars)
+	if i < 0 {
+		return buf, "", rune(0)
+	}
 
-	var (
-		key, val string
-		err      error
-		pos      int
-	)
+	r, n := utf8.DecodeRuneInString(buf[i:])
+	return buf[:i], buf[i+n:], r
+}
 
-	for {
-		before := len(rest)
-		key, rest, err = ReadKey(rest)
-		pos += before - len(rest)
+// ReadKey from the string
+//
+// A key is either all characters before the equals sign, or a word group
+// quoted in the same ways as a value, again before an equals sign. The
+// grammar is described in full with the state machine that reads it.
+//
+// If the key is quoted, only whitespace may follow the closing quote
+// before the equals sign.
+//
+// It returns the key, a string with the remainder of the characters after
+// the equals sign or an error.
+func ReadKey(buf string) (string, string, error) {
+	s := &scanner{input: buf}
+
+	err := s.scan(stateValue)
+
+	var pe *ParseError
+	switch {
+	case errors.As(err, &pe) && pe.Kind == EmptyKey:
+		return "", buf[pe.Offset+1:], nil
+	case errors.As(err, &pe) && pe.Kind == MissingEquals:
+		return pe.Key, "", nil
+	case errors.As(err, &pe):
+		return "", buf[pe.Offset:], pe.Err
+	}
 
-		if err != nil {
-			return nil, fmt.Errorf("unable to read key ending at char %d\n%w", pos-1, err)
-		}
+	return s.key, buf[s.pos:], nil
+}
+
+// ReadValue from the string
+//
+// A value is either all characters up to the first space, tab or
+// newline, a single or double quoted word group, or a group quoted with
+// three double or three single quotes that may contain quotes and
+// newlines. The grammar is described in full with the state machine that
+// reads it.
+//
+// If the value is quoted, only whitespace may follow the closing quote.
+//
+// It returns the value, a string with the remainder of the characters
+// after the whitespace that ends the value or an error.
+func ReadValue(buf string) (string, string, error) {
+	s := &scanner{input: buf, state: stateValue}
 
-		if len(key) == 0 {
-			return nil, fmt.Errorf("unable to have empty key ending at char %d", pos-1)
+	if err := s.scan(stateKey); err != nil {
+		var pe *ParseError
+		if errors.As(err, &pe) {
+			return "", buf[pe.Offset:], pe.Err
 		}
+		return "", "", err
+	}
 
-		before = len(rest)
-		val, rest, err = ReadValue(rest)
-		pos += before - len(rest)
+	return s.pairs[0].Value, buf[s.pos:], nil
+}
 
+// ParseLabels from the string
+//
+// The string is a sequence of key=value pairs separated by spaces, tabs
+// or newlines, read as described with the state machine in scanner.go.
+// Each value is then expanded with Expand against the environment.
+//
+// It returns a map of keys to values, where the last of any duplicate
+// keys wins, or an error. Syntax errors are returned as a *ParseError
+// describing where and why parsing failed. A JSON object is read as
+// described with ParseLabelList.
+func ParseLabels(input string) (map[string]string, error) {
+	l, err := ParseLabelList(input)
+	if err != nil {
+		return nil, err
+	}
+
+	m := make(map[string]string)
+	for _, l := range l {
+		m[l.Key] = l.Value
+	}
+
+	return m, nil
+}
+
+// ParseLabelList from the string
+//
+// It reads the same syntax as ParseLabels but returns the labels in the
+// order they appear, including any duplicate keys.
+//
+// If the first character other than whitespace is '{', the string is
+// instead read as a JSON object of strings to strings, such as the
+// labels printed by docker inspect. Its values are expanded in the same
+// way and any other type of value is rejected.
+func ParseLabelList(input string) ([]libcnb.Label, error) {
+	var pairs []labelPair
+
+	if isJSONObject(input) {
+		l, err := parseJSONLabels(input)
 		if err != nil {
-			if len(rest) > 0 {
-				pos -= 1
-			}
-			return nil, fmt.Errorf("unable to read value ending at char %d\n%w", pos, err)
+			return nil, err
 		}
+		pairs = l
+	} else {
+		s := &scanner{input: input}
+		if err := s.scan(stateEnd); err != nil {
+			return nil, err
+		}
+		pairs = s.pairs
+	}
 
-		m[key] = val
-
-		if rest == "" {
-			return m, nil
+	labels := make([]libcnb.Label, 0, len(pairs))
+	for _, p := range pairs {
+		val, err := Expand(p.Value, os.LookupEnv)
+		if err != nil {
+			return nil, fmt.Errorf("unable to expand value of label %s\n%w", p.Key, err)
 		}
+
+		labels = append(labels, libcnb.Label{Key: p.Key, Value: val})
 	}
+
+	return labels, nil
 }
@@ -17,10 +17,18 @@
 package labels_test
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
 	"testing"
+	"time"
 
+	"github.com/BurntSushi/toml"
 	"github.com/buildpacks/libcnb/v2"
 	. "github.com/onsi/gomega"
 	"github.com/paketo-buildpacks/libpak/v2/log"
@@ -37,36 +45,943 @@ func testBuild(t *testing.T, context spec.G, it spec.S) {
 		ctx    libcnb.BuildContext
 	)
 
+	it.Before(func() {
+		ctx.Layers.Path = t.TempDir()
+	})
+
+	// build runs the build, checks that the provenance layer records each label, or that there are no layers
+	// without labels, and returns the result without its layers, which are covered on their own.
+	build := func() (libcnb.BuildResult, error) {
+		result, err := labels.NewBuild(logger)(ctx)
+		if err != nil || len(result.Labels) == 0 {
+			Expect(result.Layers).To(BeEmpty())
+			return result, err
+		}
+
+		Expect(result.Layers).To(HaveLen(1))
+		Expect(result.Layers[0].Name).To(Equal("provenance"))
+
+		b, e := os.ReadFile(filepath.Join(ctx.Layers.Path, "provenance", "provenance.json"))
+		Expect(e).NotTo(HaveOccurred())
+
+		var provenance struct {
+			Labels []libcnb.Label `json:"labels"`
+		}
+		Expect(json.Unmarshal(b, &provenance)).To(Succeed())
+		Expect(provenance.Labels).To(Equal(result.Labels))
+
+		result.Layers = nil
+		return result, err
+	}
+
 	assertMap := func(args string, expected map[string]string, err string) {
 		m, e := labels.ParseLabels(args)
 
-		if err != "" {
-			Expect(e).To(MatchError(ContainSubstring(err)))
-			return
-		}
+		if err != "" {
+			Expect(e).To(MatchError(ContainSubstring(err)))
+			return
+		}
+
+		Expect(e).ToNot(HaveOccurred())
+
+		for k, v := range expected {
+			Expect(m).To(HaveKeyWithValue(k, v))
+		}
+	}
+
+	context("$BP_IMAGE_LABELS", func() {
+		it.Before(func() {
+			t.Setenv("BP_IMAGE_LABELS", `alpha=bravo charlie="delta echo" foxtrot='golf hotel'`)
+		})
+
+		it("sets image labels", func() {
+			Expect(build()).To(Equal(libcnb.BuildResult{
+				Labels: []libcnb.Label{
+					{Key: "alpha", Value: "bravo"},
+					{Key: "charlie", Value: "delta echo"},
+					{Key: "foxtrot", Value: "golf hotel"},
+				},
+				PersistentMetadata: map[string]interface{}{},
+			}))
+		})
+	})
+
+	context("labels file", func() {
+		it.Before(func() {
+			ctx.ApplicationPath = t.TempDir()
+			Expect(os.WriteFile(filepath.Join(ctx.ApplicationPath, "labels.toml"), []byte(`
+alpha = "file"
+"com.example.team" = "platform"
+`), 0644)).To(Succeed())
+		})
+
+		it.After(func() {
+			ctx.ApplicationPath = ""
+		})
+
+		it("sets image labels from the file", func() {
+			Expect(build()).To(Equal(libcnb.BuildResult{
+				Labels: []libcnb.Label{
+					{Key: "alpha", Value: "file"},
+					{Key: "com.example.team", Value: "platform"},
+				},
+				PersistentMetadata: map[string]interface{}{},
+			}))
+		})
+
+		it("prefers $BP_IMAGE_LABELS over the file", func() {
+			t.Setenv("BP_IMAGE_LABELS", `alpha=bravo`)
+
+			Expect(build()).To(Equal(libcnb.BuildResult{
+				Labels: []libcnb.Label{
+					{Key: "alpha", Value: "bravo"},
+					{Key: "com.example.team", Value: "platform"},
+				},
+				PersistentMetadata: map[string]interface{}{},
+			}))
+		})
+
+		it("prefers $BP_OCI_* over the file", func() {
+			Expect(os.WriteFile(filepath.Join(ctx.ApplicationPath, "labels.toml"), []byte(`
+"org.opencontainers.image.title" = "file"
+`), 0644)).To(Succeed())
+			t.Setenv("BP_OCI_TITLE", "environment")
+
+			Expect(build()).To(Equal(libcnb.BuildResult{
+				Labels: []libcnb.Label{
+					{Key: "org.opencontainers.image.title", Value: "environment"},
+				},
+				PersistentMetadata: map[string]interface{}{},
+			}))
+		})
+
+		it("fails if $BP_IMAGE_LABELS_FILE does not exist", func() {
+			t.Setenv("BP_IMAGE_LABELS_FILE", "missing.toml")
+
+			_, err := labels.NewBuild(logger)(ctx)
+			Expect(err).To(MatchError(ContainSubstring("unable to read labels file")))
+		})
+	})
+
+	context("Dockerfile", func() {
+		it.Before(func() {
+			ctx.ApplicationPath = t.TempDir()
+			writeFiles(t, ctx.ApplicationPath, map[string]string{"Dockerfile": `FROM ubuntu:24.04
+ARG REVISION
+LABEL alpha=dockerfile \
+      com.example.team="platform" \
+      org.opencontainers.image.revision=$REVISION
+`})
+		})
+
+		it.After(func() {
+			ctx.ApplicationPath = ""
+		})
+
+		it("sets image labels from the Dockerfile", func() {
+			Expect(build()).To(Equal(libcnb.BuildResult{
+				Labels: []libcnb.Label{
+					{Key: "alpha", Value: "dockerfile"},
+					{Key: "com.example.team", Value: "platform"},
+				},
+				PersistentMetadata: map[string]interface{}{},
+			}))
+		})
+
+		it("prefers the labels file over the Dockerfile", func() {
+			writeFiles(t, ctx.ApplicationPath, map[string]string{"labels.toml": `alpha = "file"`})
+
+			Expect(build()).To(Equal(libcnb.BuildResult{
+				Labels: []libcnb.Label{
+					{Key: "alpha", Value: "file"},
+					{Key: "com.example.team", Value: "platform"},
+				},
+				PersistentMetadata: map[string]interface{}{},
+			}))
+		})
+
+		it("fails if $BP_IMAGE_LABELS_DOCKERFILE does not exist", func() {
+			t.Setenv("BP_IMAGE_LABELS_DOCKERFILE", "missing.Dockerfile")
+
+			_, err := labels.NewBuild(logger)(ctx)
+			Expect(err).To(MatchError(ContainSubstring("unable to read Dockerfile")))
+		})
+	})
+
+	context("per-label environment variables", func() {
+		it("sets image labels", func() {
+			t.Setenv("BP_IMAGE_LABEL_1_KEY", "com.example.description")
+			t.Setenv("BP_IMAGE_LABEL_1_VALUE", `a "quoted" value for ${TEAM}`)
+			t.Setenv("BP_IMAGE_LABEL__com_example_team", "platform")
+			t.Setenv("TEAM", "infra")
+
+			Expect(build()).To(Equal(libcnb.BuildResult{
+				Labels: []libcnb.Label{
+					{Key: "com.example.description", Value: `a "quoted" value for infra`},
+					{Key: "com.example.team", Value: "platform"},
+				},
+				PersistentMetadata: map[string]interface{}{},
+			}))
+		})
+
+		it("resolves conflicts with $BP_IMAGE_LABELS", func() {
+			t.Setenv("BP_IMAGE_LABELS", "com.example.team=java")
+			t.Setenv("BP_IMAGE_LABEL__com_example_team", "platform")
+			t.Setenv("BP_IMAGE_LABELS_CONFLICTS", "error")
+
+			_, err := build()
+			Expect(err).To(MatchError(`label com.example.team is set to "java" by $BP_IMAGE_LABELS and to "platform" by $BP_IMAGE_LABEL__com_example_team`))
+		})
+
+		it("fails on invalid variables", func() {
+			t.Setenv("BP_IMAGE_LABEL_1_VALUE", "platform")
+
+			_, err := build()
+			Expect(err).To(MatchError(ContainSubstring("unable to read label environment variables")))
+		})
+	})
+
+	context("$BP_IMAGE_LABELS_CONFLICTS", func() {
+		it.Before(func() {
+			t.Setenv("BP_OCI_TITLE", "from-oci")
+			t.Setenv("BP_IMAGE_LABELS", "alpha=1 org.opencontainers.image.title=from-labels alpha=2")
+		})
+
+		it("uses the last value by default", func() {
+			Expect(build()).To(Equal(libcnb.BuildResult{
+				Labels: []libcnb.Label{
+					{Key: "alpha", Value: "2"},
+					{Key: "org.opencontainers.image.title", Value: "from-labels"},
+				},
+				PersistentMetadata: map[string]interface{}{},
+			}))
+		})
+
+		it("uses the first value with first-wins", func() {
+			t.Setenv("BP_IMAGE_LABELS_CONFLICTS", "first-wins")
+
+			Expect(build()).To(Equal(libcnb.BuildResult{
+				Labels: []libcnb.Label{
+					{Key: "alpha", Value: "1"},
+					{Key: "org.opencontainers.image.title", Value: "from-oci"},
+				},
+				PersistentMetadata: map[string]interface{}{},
+			}))
+		})
+
+		it("sets image labels from a JSON object", func() {
+			t.Setenv("BP_OCI_TITLE", "from-oci")
+			t.Setenv("BP_IMAGE_LABELS", `{"alpha": "1", "org.opencontainers.image.title": "from-json", "alpha": "2"}`)
+
+			Expect(build()).To(Equal(libcnb.BuildResult{
+				Labels: []libcnb.Label{
+					{Key: "alpha", Value: "2"},
+					{Key: "org.opencontainers.image.title", Value: "from-json"},
+				},
+				PersistentMetadata: map[string]interface{}{},
+			}))
+		})
+
+		it("resolves conflicts in a JSON object", func() {
+			t.Setenv("BP_IMAGE_LABELS", `{"alpha": "1", "alpha": "2"}`)
+			t.Setenv("BP_IMAGE_LABELS_CONFLICTS", "error")
+
+			_, err := build()
+			Expect(err).To(MatchError(`label alpha is set to "1" by $BP_IMAGE_LABELS and to "2" by $BP_IMAGE_LABELS`))
+		})
+
+		it("fails with error", func() {
+			t.Setenv("BP_IMAGE_LABELS_CONFLICTS", "error")
+
+			_, err := labels.NewBuild(logger)(ctx)
+			Expect(err).To(MatchError(`label org.opencontainers.image.title is set to "from-oci" by $BP_OCI_TITLE and to "from-labels" by $BP_IMAGE_LABELS`))
+		})
+	})
+
+	context("build plan", func() {
+		it.Before(func() {
+			ctx.Plan.Entries = []libcnb.BuildpackPlanEntry{
+				{Name: "jvm-application"},
+				{Name: "image-labels", Metadata: map[string]interface{}{
+					"labels": map[string]interface{}{"com.example.runtime": "jre-21", "com.example.team": "java"},
+				}},
+				{Name: "image-labels", Metadata: map[string]interface{}{
+					"labels": map[string]interface{}{"com.example.runtime": "node-22", "com.example.tier": "web"},
+				}},
+			}
+		})
+
+		it.After(func() {
+			ctx.Plan.Entries = nil
+		})
+
+		it("sets labels contributed by other buildpacks", func() {
+			Expect(build()).To(Equal(libcnb.BuildResult{
+				Labels: []libcnb.Label{
+					{Key: "com.example.runtime", Value: "jre-21"},
+					{Key: "com.example.team", Value: "java"},
+					{Key: "com.example.tier", Value: "web"},
+				},
+				PersistentMetadata: map[string]interface{}{},
+			}))
+		})
+
+		it("prefers labels configured by the user", func() {
+			t.Setenv("BP_IMAGE_LABELS", "com.example.team=platform")
+
+			Expect(build()).To(Equal(libcnb.BuildResult{
+				Labels: []libcnb.Label{
+					{Key: "com.example.runtime", Value: "jre-21"},
+					{Key: "com.example.team", Value: "platform"},
+					{Key: "com.example.tier", Value: "web"},
+				},
+				PersistentMetadata: map[string]interface{}{},
+			}))
+		})
+
+		it("fails on invalid labels metadata", func() {
+			ctx.Plan.Entries = []libcnb.BuildpackPlanEntry{
+				{Name: "image-labels", Metadata: map[string]interface{}{"labels": "com.example.runtime=jre-21"}},
+			}
+
+			_, err := labels.NewBuild(logger)(ctx)
+			Expect(err).To(MatchError("unable to read build plan labels\nunable to use a string as the labels of build plan entry 0"))
+		})
+	})
+
+	context("default labels", func() {
+		it.Before(func() {
+			ctx.Buildpack.Metadata = map[string]interface{}{
+				"default-labels": []map[string]interface{}{
+					{"key": "org.opencontainers.image.vendor", "value": "Example Inc."},
+					{"key": "com.example.support", "value": "${SUPPORT_EMAIL:-support@example.com}"},
+				},
+			}
+		})
+
+		it.After(func() {
+			ctx.Buildpack.Metadata = nil
+		})
+
+		it("sets default labels", func() {
+			Expect(build()).To(Equal(libcnb.BuildResult{
+				Labels: []libcnb.Label{
+					{Key: "com.example.support", Value: "support@example.com"},
+					{Key: "org.opencontainers.image.vendor", Value: "Example Inc."},
+				},
+				PersistentMetadata: map[string]interface{}{},
+			}))
+		})
+
+		it("expands default labels", func() {
+			t.Setenv("SUPPORT_EMAIL", "platform@example.com")
+
+			result, err := build()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Labels).To(ContainElement(libcnb.Label{Key: "com.example.support", Value: "platform@example.com"}))
+		})
+
+		it("prefers labels from any other source", func() {
+			t.Setenv("BP_OCI_VENDOR", "Acme")
+			ctx.Plan.Entries = []libcnb.BuildpackPlanEntry{
+				{Name: "image-labels", Metadata: map[string]interface{}{"labels": map[string]interface{}{"com.example.support": "java@example.com"}}},
+			}
+			defer func() { ctx.Plan.Entries = nil }()
+
+			Expect(build()).To(Equal(libcnb.BuildResult{
+				Labels: []libcnb.Label{
+					{Key: "com.example.support", Value: "java@example.com"},
+					{Key: "org.opencontainers.image.vendor", Value: "Acme"},
+				},
+				PersistentMetadata: map[string]interface{}{},
+			}))
+		})
+
+		it("fails on invalid default labels", func() {
+			ctx.Buildpack.Metadata = map[string]interface{}{"default-labels": "vendor=Example"}
+
+			_, err := build()
+			Expect(err).To(MatchError(ContainSubstring("unable to read default labels")))
+		})
+	})
+
+	context("label policy", func() {
+		it.Before(func() {
+			ctx.ApplicationPath = t.TempDir()
+			Expect(os.WriteFile(filepath.Join(ctx.ApplicationPath, "policy.toml"), []byte(`
+required = ["org.opencontainers.image.source", "org.opencontainers.image.vendor", "com.example.owner"]
+forbidden = ["com.example.internal.*"]
+`), 0644)).To(Succeed())
+
+			t.Setenv("BP_IMAGE_LABELS_POLICY", "policy.toml")
+			t.Setenv("BP_OCI_VENDOR", "Example Inc.")
+			t.Setenv("BP_IMAGE_LABELS", "com.example.owner=platform com.example.internal.cost-center=42")
+		})
+
+		it.After(func() {
+			ctx.ApplicationPath = ""
+			ctx.Buildpack.Metadata = nil
+		})
+
+		it("fails with every violation", func() {
+			ctx.Buildpack.Metadata = map[string]interface{}{
+				"label-policy": map[string]interface{}{
+					"allowed": map[string]interface{}{"org.opencontainers.image.vendor": "Example"},
+				},
+			}
+
+			_, err := labels.NewBuild(logger)(ctx)
+			Expect(err).To(MatchError(fmt.Sprintf(`labels do not satisfy the label policy
+buildpack.toml: label org.opencontainers.image.vendor="Example Inc." does not match Example
+%[1]s: required label org.opencontainers.image.source is not set
+%[1]s: label com.example.internal.cost-center is forbidden by com.example.internal.*`, filepath.Join(ctx.ApplicationPath, "policy.toml"))))
+		})
+
+		it("passes when the policy is satisfied", func() {
+			t.Setenv("BP_OCI_SOURCE", "https://github.com/example/app")
+			t.Setenv("BP_IMAGE_LABELS", "com.example.owner=platform")
+
+			Expect(build()).To(Equal(libcnb.BuildResult{
+				Labels: []libcnb.Label{
+					{Key: "com.example.owner", Value: "platform"},
+					{Key: "org.opencontainers.image.source", Value: "https://github.com/example/app"},
+					{Key: "org.opencontainers.image.vendor", Value: "Example Inc."},
+				},
+				PersistentMetadata: map[string]interface{}{},
+			}))
+		})
+	})
+
+	context("provenance", func() {
+		var buf *bytes.Buffer
+
+		it.Before(func() {
+			buf = &bytes.Buffer{}
+			ctx.ApplicationPath = t.TempDir()
+			Expect(os.WriteFile(filepath.Join(ctx.ApplicationPath, "labels.yaml"), []byte("alpha: file\ncharlie: file\n"), 0644)).To(Succeed())
+			ctx.Plan.Entries = []libcnb.BuildpackPlanEntry{
+				{Name: "image-labels", Metadata: map[string]interface{}{"labels": map[string]interface{}{"delta": "plan"}}},
+			}
+
+			t.Setenv("BP_OCI_TITLE", "my app")
+			t.Setenv("BP_IMAGE_LABELS", "alpha=env bravo=env")
+		})
+
+		it.After(func() {
+			ctx.ApplicationPath = ""
+			ctx.Plan.Entries = nil
+		})
+
+		it("records the origin of each label", func() {
+			result, err := labels.NewBuild(log.NewPaketoLogger(buf))(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(result.Layers).To(HaveLen(1))
+			Expect(result.Layers[0].Name).To(Equal("provenance"))
+			Expect(result.Layers[0].LayerTypes).To(Equal(libcnb.LayerTypes{Build: true}))
+
+			b, err := os.ReadFile(filepath.Join(ctx.Layers.Path, "provenance", "provenance.json"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(b).To(MatchJSON(fmt.Sprintf(`{"labels": [
+				{"key": "alpha", "value": "env", "origin": {"kind": "environment", "name": "BP_IMAGE_LABELS"}},
+				{"key": "bravo", "value": "env", "origin": {"kind": "environment", "name": "BP_IMAGE_LABELS"}},
+				{"key": "charlie", "value": "file", "origin": {"kind": "file", "name": %q, "line": 2}},
+				{"key": "delta", "value": "plan", "origin": {"kind": "build-plan", "name": "0"}},
+				{"key": "org.opencontainers.image.title", "value": "my app", "origin": {"kind": "environment", "name": "BP_OCI_TITLE"}}
+			]}`, filepath.Join(ctx.ApplicationPath, "labels.yaml"))))
+
+			Expect(buf.String()).To(ContainSubstring("Label provenance"))
+			Expect(buf.String()).To(ContainSubstring("charlie                         " + filepath.Join(ctx.ApplicationPath, "labels.yaml") + ":2"))
+		})
+
+		it("does not contribute a layer without labels", func() {
+			ctx.ApplicationPath = t.TempDir()
+			ctx.Plan.Entries = nil
+			Expect(os.Unsetenv("BP_OCI_TITLE")).To(Succeed())
+			Expect(os.Unsetenv("BP_IMAGE_LABELS")).To(Succeed())
+
+			result, err := labels.NewBuild(log.NewPaketoLogger(buf))(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Layers).To(BeEmpty())
+			Expect(buf.String()).NotTo(ContainSubstring("Label provenance"))
+		})
+	})
+
+	context("$BP_IMAGE_LABELS_RUNTIME", func() {
+		it.Before(func() {
+			t.Setenv("BP_OCI_REVISION", "abc123")
+		})
+
+		it("does not contribute a runtime layer by default", func() {
+			result, err := labels.NewBuild(logger)(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Layers).To(HaveLen(1))
+			Expect(result.Layers[0].Name).To(Equal("provenance"))
+		})
+
+		it("contributes the labels to a launch layer", func() {
+			t.Setenv("BP_IMAGE_LABELS_RUNTIME", "true")
+
+			result, err := labels.NewBuild(logger)(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Layers).To(HaveLen(2))
+			Expect(result.Layers[1].Name).To(Equal("runtime-labels"))
+			Expect(result.Layers[1].LayerTypes).To(Equal(libcnb.LayerTypes{Launch: true}))
+			Expect(result.Layers[1].LaunchEnvironment).NotTo(HaveKey("BPI_LABEL_ORG_OPENCONTAINERS_IMAGE_REVISION.default"))
+			Expect(filepath.Join(ctx.Layers.Path, "runtime-labels", "labels.json")).To(BeARegularFile())
+		})
+
+		it("exports the labels with $BP_IMAGE_LABELS_RUNTIME_ENV", func() {
+			t.Setenv("BP_IMAGE_LABELS_RUNTIME", "true")
+			t.Setenv("BP_IMAGE_LABELS_RUNTIME_ENV", "true")
+
+			result, err := labels.NewBuild(logger)(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Layers[1].LaunchEnvironment).To(HaveKeyWithValue("BPI_LABEL_ORG_OPENCONTAINERS_IMAGE_REVISION.default", "abc123"))
+		})
+	})
+
+	context("ordering", func() {
+		it.Before(func() {
+			ctx.ApplicationPath = t.TempDir()
+			Expect(os.WriteFile(filepath.Join(ctx.ApplicationPath, "labels.toml"), []byte(`
+zulu = "file"
+mike = "file"
+`), 0644)).To(Succeed())
+
+			ctx.Plan.Entries = []libcnb.BuildpackPlanEntry{
+				{Name: "image-labels", Metadata: map[string]interface{}{
+					"labels": map[string]interface{}{"kilo": "plan", "bravo": "plan", "yankee": "plan"},
+				}},
+			}
+
+			for k := range labels.Labels {
+				t.Setenv(k, k)
+			}
+			t.Setenv("BP_IMAGE_LABELS", "xray=1 alpha=2 zulu=3 lima=4")
+		})
+
+		it.After(func() {
+			ctx.ApplicationPath = ""
+			ctx.Plan.Entries = nil
+		})
+
+		it("emits identical labels on every build", func() {
+			var expected []byte
+
+			for i := 0; i < 100; i++ {
+				result, err := labels.NewBuild(logger)(ctx)
+				Expect(err).NotTo(HaveOccurred())
+
+				var b bytes.Buffer
+				Expect(toml.NewEncoder(&b).Encode(result)).To(Succeed())
+
+				if expected == nil {
+					expected = b.Bytes()
+				}
+				Expect(b.Bytes()).To(Equal(expected))
+			}
+		})
+
+		it("emits each label once sorted by key", func() {
+			result, err := labels.NewBuild(logger)(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			keys := make([]string, len(result.Labels))
+			for i, l := range result.Labels {
+				keys[i] = l.Key
+			}
+			Expect(sort.StringsAreSorted(keys)).To(BeTrue())
+			Expect(keys).To(HaveLen(len(labels.Labels) + 8))
+		})
+	})
+
+	context("$BP_IMAGE_LABELS_CI", func() {
+		it.Before(func() {
+			t.Setenv("BP_IMAGE_LABELS_CI", "true")
+			t.Setenv("GITHUB_ACTIONS", "true")
+			t.Setenv("GITHUB_SERVER_URL", "https://github.com")
+			t.Setenv("GITHUB_REPOSITORY", "example/my-app")
+			t.Setenv("GITHUB_SHA", "abc123")
+			t.Setenv("GITHUB_HEAD_REF", "")
+			t.Setenv("GITHUB_REF_NAME", "main")
+			t.Setenv("GITHUB_RUN_ID", "42")
+			t.Setenv("GITHUB_RUN_NUMBER", "7")
+		})
+
+		it("sets labels from the CI provider", func() {
+			Expect(build()).To(Equal(libcnb.BuildResult{
+				Labels: []libcnb.Label{
+					{Key: "io.buildpacks.ci.build-number", Value: "7"},
+					{Key: "io.buildpacks.ci.provider", Value: "github-actions"},
+					{Key: "io.buildpacks.ci.run-url", Value: "https://github.com/example/my-app/actions/runs/42"},
+					{Key: "org.opencontainers.image.ref.name", Value: "main"},
+					{Key: "org.opencontainers.image.revision", Value: "abc123"},
+					{Key: "org.opencontainers.image.source", Value: "https://github.com/example/my-app"},
+				},
+				PersistentMetadata: map[string]interface{}{},
+			}))
+		})
+
+		it("does not override explicit labels", func() {
+			t.Setenv("BP_OCI_REVISION", "v1.0.0")
+			t.Setenv("BP_IMAGE_LABELS", "io.buildpacks.ci.provider=custom")
+
+			result, err := build()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Labels).To(ContainElement(libcnb.Label{Key: "org.opencontainers.image.revision", Value: "v1.0.0"}))
+			Expect(result.Labels).To(ContainElement(libcnb.Label{Key: "io.buildpacks.ci.provider", Value: "custom"}))
+		})
+
+		it("does not read the CI provider by default", func() {
+			t.Setenv("BP_IMAGE_LABELS_CI", "false")
+
+			Expect(build()).To(Equal(libcnb.BuildResult{PersistentMetadata: map[string]interface{}{}}))
+		})
+	})
+
+	context("$BP_IMAGE_LABELS_GIT", func() {
+		it.Before(func() {
+			t.Setenv("BP_IMAGE_LABELS_GIT", "true")
+
+			ctx.ApplicationPath = t.TempDir()
+			writeFiles(t, ctx.ApplicationPath, map[string]string{
+				".git/HEAD":            "ref: refs/heads/main\n",
+				".git/refs/heads/main": testCommit + "\n",
+				".git/config":          "[remote \"origin\"]\n\turl = https://github.com/example/app\n",
+			})
+		})
+
+		it.After(func() {
+			ctx.ApplicationPath = ""
+		})
+
+		it("sets labels from git", func() {
+			Expect(build()).To(Equal(libcnb.BuildResult{
+				Labels: []libcnb.Label{
+					{Key: "org.opencontainers.image.ref.name", Value: "main"},
+					{Key: "org.opencontainers.image.revision", Value: testCommit},
+					{Key: "org.opencontainers.image.source", Value: "https://github.com/example/app"},
+				},
+				PersistentMetadata: map[string]interface{}{},
+			}))
+		})
+
+		it("does not override explicit labels", func() {
+			t.Setenv("BP_OCI_REVISION", "explicit")
+			t.Setenv("BP_IMAGE_LABELS", "org.opencontainers.image.ref.name=release")
+
+			Expect(build()).To(Equal(libcnb.BuildResult{
+				Labels: []libcnb.Label{
+					{Key: "org.opencontainers.image.ref.name", Value: "release"},
+					{Key: "org.opencontainers.image.revision", Value: "explicit"},
+					{Key: "org.opencontainers.image.source", Value: "https://github.com/example/app"},
+				},
+				PersistentMetadata: map[string]interface{}{},
+			}))
+		})
+
+		it("ignores applications without git", func() {
+			Expect(os.RemoveAll(filepath.Join(ctx.ApplicationPath, ".git"))).To(Succeed())
+
+			Expect(build()).To(Equal(libcnb.BuildResult{
+				PersistentMetadata: map[string]interface{}{},
+			}))
+		})
+	})
+
+	context("$BP_IMAGE_LABELS_INFER", func() {
+		it.Before(func() {
+			t.Setenv("BP_IMAGE_LABELS_INFER", "true")
+
+			ctx.ApplicationPath = t.TempDir()
+			writeFiles(t, ctx.ApplicationPath, map[string]string{
+				"package.json": `{"name": "my-app", "version": "1.2.3"}`,
+			})
+		})
+
+		it.After(func() {
+			ctx.ApplicationPath = ""
+		})
+
+		it("sets labels inferred from manifests", func() {
+			Expect(build()).To(Equal(libcnb.BuildResult{
+				Labels: []libcnb.Label{
+					{Key: "org.opencontainers.image.title", Value: "my-app"},
+					{Key: "org.opencontainers.image.version", Value: "1.2.3"},
+				},
+				PersistentMetadata: map[string]interface{}{},
+			}))
+		})
+
+		it("prefers explicit configuration", func() {
+			t.Setenv("BP_OCI_VERSION", "2.0.0")
 
-		Expect(e).ToNot(HaveOccurred())
+			Expect(build()).To(Equal(libcnb.BuildResult{
+				Labels: []libcnb.Label{
+					{Key: "org.opencontainers.image.title", Value: "my-app"},
+					{Key: "org.opencontainers.image.version", Value: "2.0.0"},
+				},
+				PersistentMetadata: map[string]interface{}{},
+			}))
+		})
+	})
 
-		for k, v := range expected {
-			Expect(m).To(HaveKeyWithValue(k, v))
-		}
-	}
+	context("$BP_IMAGE_LABELS_AUTHORS", func() {
+		it.Before(func() {
+			t.Setenv("BP_IMAGE_LABELS_AUTHORS", "true")
+			ctx.ApplicationPath = filepath.Join("testdata", "authors", "github", "app")
+		})
 
-	context("$BP_IMAGE_LABELS", func() {
+		it.After(func() {
+			ctx.ApplicationPath = ""
+		})
+
+		it("sets the authors from CODEOWNERS", func() {
+			Expect(build()).To(Equal(libcnb.BuildResult{
+				Labels:             []libcnb.Label{{Key: "org.opencontainers.image.authors", Value: "@example/platform, Jane.Doe@Example.com"}},
+				PersistentMetadata: map[string]interface{}{},
+			}))
+		})
+
+		it("prefers explicit configuration", func() {
+			t.Setenv("BP_OCI_AUTHORS", "Example Corp.")
+
+			Expect(build()).To(Equal(libcnb.BuildResult{
+				Labels:             []libcnb.Label{{Key: "org.opencontainers.image.authors", Value: "Example Corp."}},
+				PersistentMetadata: map[string]interface{}{},
+			}))
+		})
+	})
+
+	context("$BP_IMAGE_LABELS_README", func() {
 		it.Before(func() {
-			t.Setenv("BP_IMAGE_LABELS", `alpha=bravo charlie="delta echo" foxtrot='golf hotel'`)
+			t.Setenv("BP_IMAGE_LABELS_README", "true")
+
+			ctx.ApplicationPath = t.TempDir()
+			writeFiles(t, ctx.ApplicationPath, map[string]string{
+				"README.md": "# My App\n\nAnswers every question, quickly.\n",
+			})
 		})
 
-		it("sets image labels", func() {
-			Expect(labels.NewBuild(logger)(ctx)).To(Equal(libcnb.BuildResult{
+		it.After(func() {
+			ctx.ApplicationPath = ""
+		})
+
+		it("sets the title and description from the README", func() {
+			Expect(build()).To(Equal(libcnb.BuildResult{
 				Labels: []libcnb.Label{
-					{Key: "alpha", Value: "bravo"},
-					{Key: "charlie", Value: "delta echo"},
-					{Key: "foxtrot", Value: "golf hotel"},
+					{Key: "org.opencontainers.image.description", Value: "Answers every question, quickly."},
+					{Key: "org.opencontainers.image.title", Value: "My App"},
+				},
+				PersistentMetadata: map[string]interface{}{},
+			}))
+		})
+
+		it("truncates the description", func() {
+			t.Setenv("BP_IMAGE_LABELS_README_LENGTH", "26")
+
+			Expect(build()).To(Equal(libcnb.BuildResult{
+				Labels: []libcnb.Label{
+					{Key: "org.opencontainers.image.description", Value: "Answers every question…"},
+					{Key: "org.opencontainers.image.title", Value: "My App"},
+				},
+				PersistentMetadata: map[string]interface{}{},
+			}))
+		})
+
+		it("fails with an invalid length", func() {
+			t.Setenv("BP_IMAGE_LABELS_README_LENGTH", "short")
+
+			_, err := build()
+			Expect(err).To(MatchError(`unsupported $BP_IMAGE_LABELS_README_LENGTH "short", must be a number of characters or 0`))
+		})
+
+		it("prefers explicit configuration", func() {
+			t.Setenv("BP_OCI_TITLE", "my-app")
+			t.Setenv("BP_OCI_DESCRIPTION", "An app")
+
+			Expect(build()).To(Equal(libcnb.BuildResult{
+				Labels: []libcnb.Label{
+					{Key: "org.opencontainers.image.description", Value: "An app"},
+					{Key: "org.opencontainers.image.title", Value: "my-app"},
+				},
+				PersistentMetadata: map[string]interface{}{},
+			}))
+		})
+	})
+
+	context("$BP_IMAGE_LABELS_LICENSE", func() {
+		it.Before(func() {
+			t.Setenv("BP_IMAGE_LABELS_LICENSE", "true")
+			ctx.ApplicationPath = t.TempDir()
+		})
+
+		it.After(func() {
+			ctx.ApplicationPath = ""
+		})
+
+		it("sets the license identified in a license file", func() {
+			writeFiles(t, ctx.ApplicationPath, map[string]string{"LICENSE": licenseText(t, "MIT")})
+
+			Expect(build()).To(Equal(libcnb.BuildResult{
+				Labels:             []libcnb.Label{{Key: "org.opencontainers.image.licenses", Value: "MIT"}},
+				PersistentMetadata: map[string]interface{}{},
+			}))
+		})
+
+		it("sets a license identified in several license files", func() {
+			writeFiles(t, ctx.ApplicationPath, map[string]string{
+				"LICENSE":    licenseText(t, "MIT"),
+				"LICENSE.md": licenseText(t, "MIT"),
+			})
+
+			Expect(build()).To(Equal(libcnb.BuildResult{
+				Labels:             []libcnb.Label{{Key: "org.opencontainers.image.licenses", Value: "MIT"}},
+				PersistentMetadata: map[string]interface{}{},
+			}))
+		})
+
+		it("does not set different licenses, which may be alternatives", func() {
+			writeFiles(t, ctx.ApplicationPath, map[string]string{
+				"LICENSE-MIT":    licenseText(t, "MIT"),
+				"LICENSE-APACHE": licenseText(t, "Apache-2.0"),
+			})
+
+			Expect(build()).To(Equal(libcnb.BuildResult{PersistentMetadata: map[string]interface{}{}}))
+		})
+
+		it("does not set a license that could be one of several", func() {
+			writeFiles(t, ctx.ApplicationPath, map[string]string{"COPYING": licenseText(t, "GPL-3.0")})
+
+			Expect(build()).To(Equal(libcnb.BuildResult{PersistentMetadata: map[string]interface{}{}}))
+		})
+
+		it("prefers explicit configuration", func() {
+			t.Setenv("BP_OCI_LICENSES", "Apache-2.0")
+			writeFiles(t, ctx.ApplicationPath, map[string]string{"LICENSE": licenseText(t, "MIT")})
+
+			Expect(build()).To(Equal(libcnb.BuildResult{
+				Labels:             []libcnb.Label{{Key: "org.opencontainers.image.licenses", Value: "Apache-2.0"}},
+				PersistentMetadata: map[string]interface{}{},
+			}))
+		})
+	})
+
+	context("$BP_IMAGE_LABELS_STRICT", func() {
+		it("warns on invalid keys", func() {
+			t.Setenv("BP_IMAGE_LABELS", `com.example.team=platform org.opencontainers.image.revison=abc alpha=bravo`)
+
+			result, err := labels.NewBuild(logger)(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Labels).To(HaveLen(3))
+		})
+
+		it("warns on invalid values and keeps them as is", func() {
+			t.Setenv("BP_OCI_CREATED", "2024-01-01 10:00")
+
+			Expect(build()).To(Equal(libcnb.BuildResult{
+				Labels: []libcnb.Label{
+					{Key: "org.opencontainers.image.created", Value: "2024-01-01 10:00"},
+				},
+				PersistentMetadata: map[string]interface{}{},
+			}))
+		})
+
+		it("fails on invalid values", func() {
+			t.Setenv("BP_IMAGE_LABELS_STRICT", "true")
+			t.Setenv("BP_OCI_LICENSES", "Apache 2.0")
+			t.Setenv("BP_OCI_CREATED", "yesterday")
+
+			_, err := labels.NewBuild(logger)(ctx)
+			Expect(err).To(MatchError(ContainSubstring(`org.opencontainers.image.licenses: value "Apache 2.0" is not a valid SPDX license expression`)))
+			Expect(err).To(MatchError(ContainSubstring(`org.opencontainers.image.created: value "yesterday" is not an RFC 3339 date-time`)))
+		})
+
+		it("fails on invalid keys", func() {
+			t.Setenv("BP_IMAGE_LABELS", `com.example.team=platform org.opencontainers.image.revison=abc alpha=bravo`)
+			t.Setenv("BP_IMAGE_LABELS_STRICT", "true")
+
+			_, err := labels.NewBuild(logger)(ctx)
+			Expect(err).To(MatchError(ContainSubstring("alpha: key should use reverse-DNS notation")))
+			Expect(err).To(MatchError(ContainSubstring("org.opencontainers.image.revison: key is not a pre-defined OCI key, did you mean org.opencontainers.image.revision?")))
+			Expect(err).NotTo(MatchError(ContainSubstring("com.example.team")))
+		})
+
+		it("only warns on versions that are not semantic versions", func() {
+			t.Setenv("BP_OCI_VERSION", "1.0-SNAPSHOT")
+			t.Setenv("BP_IMAGE_LABELS_STRICT", "true")
+
+			Expect(build()).To(Equal(libcnb.BuildResult{
+				Labels: []libcnb.Label{
+					{Key: "org.opencontainers.image.version", Value: "1.0-SNAPSHOT"},
+				},
+				PersistentMetadata: map[string]interface{}{},
+			}))
+		})
+
+		it("accepts keys with mixed case, underscores and trailing digits", func() {
+			t.Setenv("BP_IMAGE_LABELS", `com.example.myKey=a com.example.team2=b`)
+			t.Setenv("BP_IMAGE_LABEL__com_example_build___id", "c")
+			t.Setenv("BP_IMAGE_LABELS_STRICT", "true")
+
+			Expect(build()).To(Equal(libcnb.BuildResult{
+				Labels: []libcnb.Label{
+					{Key: "com.example.build_id", Value: "c"},
+					{Key: "com.example.myKey", Value: "a"},
+					{Key: "com.example.team2", Value: "b"},
+				},
+				PersistentMetadata: map[string]interface{}{},
+			}))
+		})
+	})
+
+	context("expansion", func() {
+		it("expands $BP_OCI_* values", func() {
+			t.Setenv("BP_OCI_VENDOR", "${VENDOR:-Example, Inc.}")
+
+			Expect(build()).To(Equal(libcnb.BuildResult{
+				Labels: []libcnb.Label{
+					{Key: "org.opencontainers.image.vendor", Value: "Example, Inc."},
+				},
+				PersistentMetadata: map[string]interface{}{},
+			}))
+		})
+
+		it("names the label of a failed expansion", func() {
+			t.Setenv("BP_OCI_VENDOR", "${VENDOR:?}")
+
+			_, err := labels.NewBuild(logger)(ctx)
+			Expect(err).To(MatchError(ContainSubstring("unable to expand $BP_OCI_VENDOR for label org.opencontainers.image.vendor\nvariable VENDOR is not set")))
+		})
+	})
+
+	context("$BP_OCI_CREATED=auto", func() {
+		it.Before(func() {
+			t.Setenv("BP_OCI_CREATED", "auto")
+		})
+
+		it("uses $SOURCE_DATE_EPOCH", func() {
+			t.Setenv("SOURCE_DATE_EPOCH", "0")
+
+			Expect(build()).To(Equal(libcnb.BuildResult{
+				Labels: []libcnb.Label{
+					{Key: "org.opencontainers.image.created", Value: "1970-01-01T00:00:00Z"},
 				},
 				PersistentMetadata: map[string]interface{}{},
 			}))
 		})
+
+		it("uses the current time", func() {
+			result, err := labels.NewBuild(logger)(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Labels).To(HaveLen(1))
+
+			created, err := time.Parse(time.RFC3339, result.Labels[0].Value)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(created.Location()).To(Equal(time.UTC))
+			Expect(created).To(BeTemporally("~", time.Now(), time.Minute))
+		})
 	})
 
 	for k, v := range labels.Labels {
@@ -77,7 +992,7 @@ func testBuild(t *testing.T, context spec.G, it spec.S) {
 			})
 
 			it(fmt.Sprintf("passes with $%s", k), func() {
-				Expect(labels.NewBuild(logger)(ctx)).To(Equal(libcnb.BuildResult{
+				Expect(build()).To(Equal(libcnb.BuildResult{
 					Labels: []libcnb.Label{
 						{Key: v, Value: "test-value"},
 					},
@@ -224,6 +1139,76 @@ func testBuild(t *testing.T, context spec.G, it spec.S) {
 				Expect(err).To(MatchError("unable to find a closing quote"))
 			})
 		})
+
+		context("Unescape", func() {
+			it("replaces escape sequences", func() {
+				Expect(labels.Unescape(`a\\b\nc\td\"e\'fé`)).To(Equal("a\\b\nc\td\"e'fé"))
+			})
+
+			it("leaves other escapes as is", func() {
+				Expect(labels.Unescape(`\$5 \x trailing\`)).To(Equal(`\$5 \x trailing\`))
+			})
+
+			it("fails on an invalid unicode escape", func() {
+				_, err := labels.Unescape(`\u12`)
+				Expect(err).To(MatchError(`unable to read escape sequence "\\u12"`))
+				Expect(errors.Is(err, labels.ErrInvalidEscape)).To(BeTrue())
+			})
+		})
+
+		context("Expand", func() {
+			lookup := func(name string) (string, bool) {
+				v, ok := map[string]string{
+					"CI_JOB_URL": "https://ci.example.com/jobs/1",
+					"EMPTY":      "",
+				}[name]
+				return v, ok
+			}
+
+			it("expands a variable", func() {
+				Expect(labels.Expand("url=${CI_JOB_URL}!", lookup)).To(Equal("url=https://ci.example.com/jobs/1!"))
+			})
+
+			it("expands an unset variable to empty", func() {
+				Expect(labels.Expand("a${TEAM}b", lookup)).To(Equal("ab"))
+			})
+
+			it("uses a default", func() {
+				Expect(labels.Expand("${TEAM:-platform}", lookup)).To(Equal("platform"))
+				Expect(labels.Expand("${EMPTY:-platform}", lookup)).To(Equal("platform"))
+				Expect(labels.Expand("${CI_JOB_URL:-platform}", lookup)).To(Equal("https://ci.example.com/jobs/1"))
+				Expect(labels.Expand("${TEAM:-${CI_JOB_URL}}", lookup)).To(Equal("https://ci.example.com/jobs/1"))
+			})
+
+			it("fails on a required variable", func() {
+				Expect(labels.Expand("${CI_JOB_URL:?must be set}", lookup)).To(Equal("https://ci.example.com/jobs/1"))
+
+				_, err := labels.Expand("${TEAM:?must be set}", lookup)
+				Expect(err).To(MatchError("variable TEAM is not set: must be set"))
+
+				_, err = labels.Expand("${EMPTY:?}", lookup)
+				Expect(err).To(MatchError("variable EMPTY is not set: parameter null or not set"))
+			})
+
+			it("leaves other dollar signs alone", func() {
+				Expect(labels.Expand("$5 $TEAM $", lookup)).To(Equal("$5 $TEAM $"))
+			})
+
+			it("unescapes a dollar sign", func() {
+				Expect(labels.Expand(`\${CI_JOB_URL} \$`, lookup)).To(Equal("${CI_JOB_URL} $"))
+			})
+
+			it("fails on malformed references", func() {
+				_, err := labels.Expand("${TEAM", lookup)
+				Expect(err).To(MatchError("unable to find closing brace for ${TEAM"))
+
+				_, err = labels.Expand("${1TEAM}", lookup)
+				Expect(err).To(MatchError(`invalid variable name "1TEAM" in ${1TEAM}`))
+
+				_, err = labels.Expand("${TEAM:=x}", lookup)
+				Expect(err).To(MatchError(`unsupported operator ":=" in ${TEAM:=x}`))
+			})
+		})
 	})
 
 	context("Parses labels", func() {
@@ -265,6 +1250,15 @@ func testBuild(t *testing.T, context spec.G, it spec.S) {
 				nil, "unable to read value ending at char 43\nunable to have characters after a trailing quote")
 		})
 
+		it("expands variables in values", func() {
+			t.Setenv("CI_JOB_URL", "https://ci.example.com/jobs/1")
+
+			assertMap(`build.url=${CI_JOB_URL} team=${TEAM:-platform} price='\$5'`,
+				map[string]string{"build.url": "https://ci.example.com/jobs/1", "team": "platform", "price": "$5"}, "")
+			assertMap(`team="${TEAM:?team is required}"`,
+				nil, "unable to expand value of label team\nvariable TEAM is not set: team is required")
+		})
+
 		it("parses with embedded equal signs", func() {
 			assertMap(`foo=bar=baz`,
 				map[string]string{"foo": "bar=baz"}, "")
@@ -272,6 +1266,42 @@ func testBuild(t *testing.T, context spec.G, it spec.S) {
 				map[string]string{"foo": "bar=baz"}, "")
 		})
 
+		it("parses a JSON object", func() {
+			t.Setenv("TEAM", "platform")
+
+			Expect(labels.ParseLabelList(` {"alpha": "bravo", "charlie": "delta ${TEAM} \"echo\"", "golf": "\\$TEAM", "alpha": "foxtrot"}`)).To(Equal([]libcnb.Label{
+				{Key: "alpha", Value: "bravo"},
+				{Key: "charlie", Value: `delta platform "echo"`},
+				{Key: "golf", Value: "$TEAM"},
+				{Key: "alpha", Value: "foxtrot"},
+			}))
+			Expect(labels.ParseLabels("{}")).To(BeEmpty())
+		})
+
+		it("fails on JSON values other than strings", func() {
+			for input, expected := range map[string]string{
+				`{"alpha": 1}`:        "unable to use a number as the value of label alpha, JSON values must be strings",
+				`{"alpha": true}`:     "unable to use a boolean as the value of label alpha, JSON values must be strings",
+				`{"alpha": null}`:     "unable to use null as the value of label alpha, JSON values must be strings",
+				`{"alpha": ["b"]}`:    "unable to use an array as the value of label alpha, JSON values must be strings",
+				`{"alpha": {"b": 1}}`: "unable to use an object as the value of label alpha, JSON values must be strings",
+			} {
+				_, err := labels.ParseLabelList(input)
+				Expect(err).To(MatchError(expected), input)
+			}
+		})
+
+		it("fails on invalid JSON", func() {
+			_, err := labels.ParseLabelList(`{"alpha": "bravo"`)
+			Expect(err).To(MatchError(ContainSubstring("unable to decode JSON labels")))
+
+			_, err = labels.ParseLabelList(`{"": "bravo"}`)
+			Expect(err).To(MatchError("unable to have an empty key in JSON labels"))
+
+			_, err = labels.ParseLabelList(`{"alpha": "bravo"} charlie=delta`)
+			Expect(err).To(MatchError("unable to have anything after the JSON labels object"))
+		})
+
 		it("fails on an empty key", func() {
 			assertMap(`""=bar`,
 				nil, "unable to have empty key ending at char 2")
@@ -293,6 +1323,317 @@ func testBuild(t *testing.T, context spec.G, it spec.S) {
 			assertMap(`some-label=(example)value some-label-2="hi t"here="" test=\'hi\'`,
 				nil, "unable to read value ending at char 52\nunable to have characters after a trailing quote")
 		})
+
+		it("parses inputs as earlier releases did", func() {
+			compatible := []struct {
+				input    string
+				expected map[string]string
+			}{
+				{`key=value`, map[string]string{"key": "value"}},
+				{`key="value"`, map[string]string{"key": "value"}},
+				{`'key'='value'`, map[string]string{"key": "value"}},
+				{`key='val\'ue'`, map[string]string{"key": "val'ue"}},
+				{`key="val\"ue"`, map[string]string{"key": `val"ue`}},
+				{`key="val\'ue"`, map[string]string{"key": "val'ue"}},
+				{`alpha=bravo charlie="delta echo" foxtrot='golf hotel'`, map[string]string{"alpha": "bravo", "charlie": "delta echo", "foxtrot": "golf hotel"}},
+				{`foo=bar=baz`, map[string]string{"foo": "bar=baz"}},
+				{`"a key"=value`, map[string]string{"a key": "value"}},
+				{`key=`, map[string]string{"key": ""}},
+				{`key="" other=''`, map[string]string{"key": "", "other": ""}},
+				{`key=a\b`, map[string]string{"key": `a\b`}},
+				{`key=a\nb`, map[string]string{"key": `a\nb`}},
+				{`key='a\nb'`, map[string]string{"key": `a\nb`}},
+				{`key=C:\Users`, map[string]string{"key": `C:\Users`}},
+				{`key=val\'ue`, map[string]string{"key": "val'ue"}},
+				{`key=it\"s`, map[string]string{"key": `it"s`}},
+				{`key=a$b`, map[string]string{"key": "a$b"}},
+				{`key="a\nb"`, map[string]string{"key": `a\nb`}},
+				{`key="a\tb"`, map[string]string{"key": `a\tb`}},
+				{`key="C:\\Users"`, map[string]string{"key": `C:\\Users`}},
+				{`key="\u2603"`, map[string]string{"key": `\u2603`}},
+				{`a=b c=d e=f`, map[string]string{"a": "b", "c": "d", "e": "f"}},
+				{`a=b `, map[string]string{"a": "b"}},
+				{`a=b c`, map[string]string{"a": "b", "c": ""}},
+				{`a= b`, map[string]string{"a": "", "b": ""}},
+				{`a="b c" d`, map[string]string{"a": "b c", "d": ""}},
+				{`"alpha"`, map[string]string{"alpha": ""}},
+				{`url=https://example.com/a?b=c&d=e`, map[string]string{"url": "https://example.com/a?b=c&d=e"}},
+			}
+
+			for _, c := range compatible {
+				Expect(labels.ParseLabels(c.input)).To(Equal(c.expected), c.input)
+			}
+		})
+
+		it("differs from earlier releases only as listed", func() {
+			t.Setenv("TEAM", "platform")
+
+			changed := []struct {
+				input    string
+				before   string
+				expected map[string]string
+				err      string
+				offset   int
+			}{
+				// quotes
+				{`key="mixed'`, `key=mixed, as either quote closed the value`,
+					nil, "unable to read value ending at char 11\nunable to find a closing quote", 11},
+				{`key=pre"quoted value"`, `key=prequoted value, as a quote could start mid-word`,
+					nil, "unable to read value ending at char 21\nunable to have a quote inside an unquoted key or value", 7},
+				{`key=value"`, `an error ending at char 10 as the quote was unterminated`,
+					nil, "unable to read value ending at char 10\nunable to have a quote inside an unquoted key or value", 9},
+				{`key=a"b"c`, `an error ending at char 9 as c followed the closing quote`,
+					nil, "unable to read value ending at char 9\nunable to have a quote inside an unquoted key or value", 5},
+				{`"a=b"=c`, `an error as a quoted key could not contain '='`,
+					map[string]string{"a=b": "c"}, "", 0},
+				{`key="""value"""`, `an error ending at char 15 as """ was an empty value followed by junk`,
+					map[string]string{"key": "value"}, "", 0},
+				{`{"a": "b"}`, `an error ending at char 9 as JSON was read as a quoted key followed by junk`,
+					map[string]string{"a": "b"}, "", 0},
+
+				// error offsets, which now point at the failure while the message still names where the key or value ends
+				{`"bad=val`, `an error at char 4, where the '=' was found`,
+					nil, "unable to read key ending at char 4\nunable to find a closing quote", 8},
+				{`"foo"junk=bar`, `an error at char 9, after the junk`,
+					nil, "unable to read key ending at char 9\nunable to have characters after a trailing quote", 5},
+				{`foo="bar"junk`, `an error at char 13, after the junk`,
+					nil, "unable to read value ending at char 13\nunable to have characters after a trailing quote", 9},
+				{`some-label=(example)value some-label-2=""hi there" test='hi'`, `an error at char 43, after the junk`,
+					nil, "unable to read value ending at char 43\nunable to have characters after a trailing quote", 41},
+				{`some-label=(example)value some-label-2=""hi t""here="" test=\'hi\'`, `an error at char 43, after the junk`,
+					nil, "unable to read value ending at char 43\nunable to have characters after a trailing quote", 41},
+				{`some-label=(example)value some-label-2="hi t"here="" test=\'hi\'`, `an error at char 52, after the junk`,
+					nil, "unable to read value ending at char 52\nunable to have characters after a trailing quote", 45},
+
+				// separators
+				{``, `an error as the key was empty`,
+					map[string]string{}, "", 0},
+				{` `, `" "="", as a space was a key`,
+					map[string]string{}, "", 0},
+				{` a=b`, `" a"=b, as spaces before a key were kept`,
+					map[string]string{"a": "b"}, "", 0},
+				{`a=b  c=d`, `" c"=d, as spaces before a key were kept`,
+					map[string]string{"a": "b", "c": "d"}, "", 0},
+				{"a=b\tc=d", `a="b\tc=d", as tabs did not separate labels`,
+					map[string]string{"a": "b", "c": "d"}, "", 0},
+				{"a=b\r\nc=d", `a="b\r\nc=d", as newlines did not separate labels`,
+					map[string]string{"a": "b", "c": "d"}, "", 0},
+				{"a=b\n", `a="b\n", as newlines did not separate labels`,
+					map[string]string{"a": "b"}, "", 0},
+				{"a=b c\n", `"c\n"="", as newlines did not separate labels`,
+					map[string]string{"a": "b", "c": ""}, "", 0},
+				{"alpha=bravo charlie\ndelta=echo", `"charlie\ndelta"=echo, as a key ran to the next '='`,
+					nil, "unable to read key ending at char 19\nunable to find an equals sign after the key", 19},
+
+				// expansion
+				{`a=${TEAM}`, `a=${TEAM}, as variables were not expanded`,
+					map[string]string{"a": "platform"}, "", 0},
+				{`key=\$HOME`, `key=\$HOME, as \$ was not an escape`,
+					map[string]string{"key": "$HOME"}, "", 0},
+				{`key="\$5"`, `key=\$5, as \$ was not an escape`,
+					map[string]string{"key": "$5"}, "", 0},
+				{`a=\\$X`, `a=\\$X, as \$ was not an escape`,
+					map[string]string{"a": `\$X`}, "", 0},
+				{`a=${X:=y}`, `a=${X:=y}, as variables were not expanded`,
+					nil, `unable to expand value of label a`, 0},
+				{`a=x${`, `a=x${, as variables were not expanded`,
+					nil, `unable to expand value of label a`, 0},
+			}
+
+			for _, c := range changed {
+				m, err := labels.ParseLabels(c.input)
+
+				if c.err != "" {
+					Expect(err).To(MatchError(ContainSubstring(c.err)), "%s was %s", c.input, c.before)
+
+					var pe *labels.ParseError
+					if errors.As(err, &pe) {
+						Expect(pe.Offset).To(Equal(c.offset), "%s was %s", c.input, c.before)
+					}
+				} else {
+					Expect(err).NotTo(HaveOccurred(), "%s was %s", c.input, c.before)
+					Expect(m).To(Equal(c.expected), "%s was %s", c.input, c.before)
+				}
+			}
+		})
+
+		it("replaces escape sequences in three double quotes", func() {
+			assertMap(`description="""line one\nline two""" columns="""a\tb""" path="""C:\\Users\\""" quote="""say \"hi\"""" snowman="""\u2603"""`,
+				map[string]string{"description": "line one\nline two", "columns": "a\tb", "path": `C:\Users\`, "quote": `say "hi"`, "snowman": "☃"}, "")
+			assertMap(`"""key\u002dname"""=value`, map[string]string{"key-name": "value"}, "")
+		})
+
+		it("does not replace escape sequences outside three double quotes", func() {
+			assertMap(`bare=a\nb single='a\tb' double="C:\Users\new" "key\u002dname"=value triple='''a\nb'''`,
+				map[string]string{"bare": `a\nb`, "single": `a\tb`, "double": `C:\Users\new`, `key\u002dname`: "value", "triple": `a\nb`}, "")
+		})
+
+		it("fails on an invalid unicode escape", func() {
+			assertMap(`key="""\u26"""`, nil, `unable to read value ending at char 7`+"\n"+`unable to read escape sequence "\\u26"`)
+			assertMap(`key="""\u26zz"""`, nil, `unable to read escape sequence "\\u26"`)
+		})
+
+		it("separates pairs with tabs and newlines", func() {
+			assertMap("alpha=bravo\tcharlie=delta\necho=foxtrot\r\n\n  golf=\"hotel\"\n",
+				map[string]string{"alpha": "bravo", "charlie": "delta", "echo": "foxtrot", "golf": "hotel"}, "")
+			assertMap("key=\"line one\nline two\"", map[string]string{"key": "line one\nline two"}, "")
+		})
+
+		it("parses an empty string", func() {
+			assertMap("", map[string]string{}, "")
+			assertMap(" \n", map[string]string{}, "")
+		})
+
+		it("parses triple quoted values", func() {
+			assertMap("description=\"\"\"\nA \"quoted\" description\nover two lines\\u0021\"\"\" title='''\nC:\\Users\\n'''",
+				map[string]string{"description": "A \"quoted\" description\nover two lines!", "title": `C:\Users\n`}, "")
+			assertMap(`key="""value`, nil, "unable to read value ending at char 12\nunable to find a closing quote")
+			assertMap(`key="""value"""junk`, nil, "unable to have characters after a trailing quote")
+		})
+
+		it("keeps duplicate keys in order", func() {
+			Expect(labels.ParseLabelList(`alpha=1 bravo=2 alpha=3`)).To(Equal([]libcnb.Label{
+				{Key: "alpha", Value: "1"},
+				{Key: "bravo", Value: "2"},
+				{Key: "alpha", Value: "3"},
+			}))
+			assertMap(`alpha=1 bravo=2 alpha=3`, map[string]string{"alpha": "3", "bravo": "2"}, "")
+		})
+
+		it("gives a last key without an equals sign an empty value", func() {
+			assertMap(`alpha=bravo charlie`, map[string]string{"alpha": "bravo", "charlie": ""}, "")
+			assertMap(`alpha= bravo`, map[string]string{"alpha": "", "bravo": ""}, "")
+			assertMap("\"alpha\" \n", map[string]string{"alpha": ""}, "")
+			assertMap(`alpha=bravo ""`, nil, "unable to have empty key ending at char 13")
+		})
+
+		it("fails without an equals sign before more labels", func() {
+			assertMap("alpha=bravo charlie\ndelta=echo",
+				nil, "unable to read key ending at char 19\nunable to find an equals sign after the key")
+			assertMap("\"alpha\"\tbravo=charlie",
+				nil, "unable to read key ending at char 13\nunable to have characters after a trailing quote")
+		})
+	})
+
+	context("ParseError", func() {
+		parseError := func(input string) *labels.ParseError {
+			_, err := labels.ParseLabels(input)
+
+			var pe *labels.ParseError
+			Expect(errors.As(err, &pe)).To(BeTrue())
+			return pe
+		}
+
+		it("describes an unterminated quote", func() {
+			pe := parseError(`alpha=bravo charlie="delta echo`)
+
+			Expect(pe.Kind).To(Equal(labels.UnterminatedQuote))
+			Expect(pe.Key).To(Equal("charlie"))
+			Expect(pe.Offset).To(Equal(31))
+			Expect(pe.Line).To(Equal(1))
+			Expect(pe.Column).To(Equal(32))
+			Expect(errors.Is(pe, labels.ErrUnterminatedQuote)).To(BeTrue())
+		})
+
+		it("describes an empty key", func() {
+			pe := parseError(`alpha=bravo ""=delta`)
+
+			Expect(pe.Kind).To(Equal(labels.EmptyKey))
+			Expect(pe.Key).To(BeEmpty())
+			Expect(pe.Offset).To(Equal(14))
+			Expect(pe.Kind.Code()).To(Equal("empty-key"))
+		})
+
+		it("describes characters after a quote", func() {
+			pe := parseError(`"alpha"junk=bravo`)
+
+			Expect(pe.Kind).To(Equal(labels.CharactersAfterQuote))
+			Expect(pe.Key).To(BeEmpty())
+			Expect(pe.Offset).To(Equal(7))
+			Expect(pe.End).To(Equal(11))
+			Expect(errors.Is(pe, labels.ErrCharactersAfterQuote)).To(BeTrue())
+		})
+
+		it("describes a missing equals sign", func() {
+			pe := parseError("alpha=bravo charlie\ndelta=echo")
+
+			Expect(pe.Kind).To(Equal(labels.MissingEquals))
+			Expect(pe.Key).To(Equal("charlie"))
+			Expect(errors.Is(pe, labels.ErrMissingEquals)).To(BeTrue())
+		})
+
+		it("counts lines and characters", func() {
+			pe := parseError("alpha='bravo\ncharlie' delta=\"écho")
+
+			Expect(pe.Line).To(Equal(2))
+			Expect(pe.Column).To(Equal(21))
+		})
+
+		it("formats the input with a caret", func() {
+			Expect(parseError(`alpha=bravo charlie="delta echo`).Format()).To(Equal(
+				"alpha=bravo charlie=\"delta echo\n" +
+					"                               ^ unable to find a closing quote (unterminated-quote)"))
+
+			Expect(parseError("alpha='bravo\n\tcharlie' delta=\"echo").Format()).To(Equal(
+				"\tcharlie' delta=\"echo\n" +
+					"\t                    ^ unable to find a closing quote (unterminated-quote)"))
+		})
+	})
+
+}
+
+func FuzzParseLabels(f *testing.F) {
+	f.Add(`alpha=bravo charlie="delta echo" foxtrot='golf hotel'`)
+	f.Add(`some-label=(example)value some-label-2=""hi there" test='hi'`)
+	f.Add("description=\"\"\"\nline one\nline \\\"two\\\"\\u0021\"\"\" title='''a'''")
+	f.Add("\"\"\"key\\u003d\"\"\"='it\\'s' path=\"\"\"C:\\\\\"\"\" \t\r\n")
+	f.Add(`price="\$5" team=${TEAM:-platform}`)
+	f.Add(`{"alpha": "bravo", "charlie": "${HOME}"}`)
+
+	f.Fuzz(func(t *testing.T, input string) {
+		m, err := labels.ParseLabels(input)
+
+		again, againErr := labels.ParseLabels(input)
+		if !reflect.DeepEqual(m, again) || fmt.Sprint(err) != fmt.Sprint(againErr) {
+			t.Fatalf("%q parsed differently on the second attempt", input)
+		}
+
+		var pe *labels.ParseError
+		if errors.As(err, &pe) {
+			if pe.Offset < 0 || pe.Offset > len(input) || pe.End < 0 || pe.End > len(input) || pe.Line < 1 || pe.Column < 1 {
+				t.Fatalf("%q returned an invalid position %+v", input, pe)
+			}
+			_ = pe.Format()
+			return
+		} else if err != nil {
+			return
+		}
+
+		s := labels.FormatLabels(m)
+		p, err := labels.ParseLabels(s)
+		if err != nil {
+			t.Fatalf("unable to parse %q, formatted from %q\n%s", s, input, err)
+		}
+		if !reflect.DeepEqual(p, m) {
+			t.Fatalf("%q parsed to %q, expected %q", s, p, m)
+		}
 	})
+}
+
+func FuzzReadKeyValue(f *testing.F) {
+	f.Add(`"also\" works" =after`)
+	f.Add(`'foo bar=`)
+	f.Add(`"""a""" b`)
+
+	f.Fuzz(func(t *testing.T, input string) {
+		if _, rest, err := labels.ReadKey(input); err == nil && len(rest) > len(input) {
+			t.Fatalf("ReadKey(%q) returned a longer remainder %q", input, rest)
+		}
+
+		if _, rest, err := labels.ReadValue(input); err == nil && len(rest) > len(input) {
+			t.Fatalf("ReadValue(%q) returned a longer remainder %q", input, rest)
+		}
 
+		labels.ReadToNext(input, "\"'= ")
+	})
 }
@@ -0,0 +1,241 @@
+/*
+ * Copyright 2018-2025 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package labels
+
+import (
+	"strings"
+
+	"github.com/buildpacks/libcnb/v2"
+)
+
+const (
+	// CIProviderLabel is the key of the label naming the CI provider that ran the build.
+	CIProviderLabel = "io.buildpacks.ci.provider"
+
+	// CIRunURLLabel is the key of the label holding the URL of the CI pipeline run or build.
+	CIRunURLLabel = "io.buildpacks.ci.run-url"
+
+	// CIBuildNumberLabel is the key of the label holding the CI build number.
+	CIBuildNumberLabel = "io.buildpacks.ci.build-number"
+)
+
+// CIMetadata is the build metadata provided by a CI provider.
+type CIMetadata struct {
+	Revision    string
+	Source      string
+	RefName     string
+	RunURL      string
+	BuildNumber string
+}
+
+// CIProvider is a CI provider recognized from the environment variables it sets.
+type CIProvider struct {
+	// Name identifies the provider and is the value of the CIProviderLabel label.
+	Name string
+
+	// Detect reports whether the build runs on the provider.
+	Detect func(getenv func(string) string) bool
+
+	// Read returns the build metadata from the environment.
+	Read func(getenv func(string) string) CIMetadata
+}
+
+// CIProviders are the supported CI providers, in the order they are detected.
+var CIProviders = []CIProvider{
+	{Name: "github-actions", Detect: isSet("GITHUB_ACTIONS"), Read: readGitHubActions},
+	{Name: "gitlab-ci", Detect: isSet("GITLAB_CI"), Read: readGitLabCI},
+	{Name: "jenkins", Detect: isSet("JENKINS_URL"), Read: readJenkins},
+	{Name: "circleci", Detect: isSet("CIRCLECI"), Read: readCircleCI},
+	{Name: "tekton", Detect: isSet("TEKTON_PIPELINE_RUN"), Read: readTekton},
+	{Name: "azure-pipelines", Detect: isSet("TF_BUILD"), Read: readAzurePipelines},
+	{Name: "buildkite", Detect: isSet("BUILDKITE"), Read: readBuildkite},
+	{Name: "bitbucket-pipelines", Detect: isSet("BITBUCKET_BUILD_NUMBER"), Read: readBitbucketPipelines},
+}
+
+// DetectCI returns the first of CIProviders that the build runs on.
+func DetectCI(getenv func(string) string) (CIProvider, bool) {
+	for _, p := range CIProviders {
+		if p.Detect(getenv) {
+			return p, true
+		}
+	}
+
+	return CIProvider{}, false
+}
+
+// CILabels returns the labels derived from the build metadata of the CI provider that the build runs on, if any:
+// the org.opencontainers.image.revision, source and ref.name labels, and the CIProviderLabel, CIRunURLLabel and
+// CIBuildNumberLabel labels. Labels without a value are omitted and credentials are removed from the source URL.
+func CILabels(getenv func(string) string) (CIProvider, []libcnb.Label, bool) {
+	p, ok := DetectCI(getenv)
+	if !ok {
+		return CIProvider{}, nil, false
+	}
+
+	m := p.Read(getenv)
+
+	var labels []libcnb.Label
+	for _, l := range []libcnb.Label{
+		{Key: Labels["BP_OCI_REVISION"], Value: m.Revision},
+		{Key: Labels["BP_OCI_SOURCE"], Value: redactURL(m.Source)},
+		{Key: Labels["BP_OCI_REF_NAME"], Value: m.RefName},
+		{Key: CIProviderLabel, Value: p.Name},
+		{Key: CIRunURLLabel, Value: redactURL(m.RunURL)},
+		{Key: CIBuildNumberLabel, Value: m.BuildNumber},
+	} {
+		if l.Value = strings.TrimSpace(l.Value); l.Value != "" {
+			labels = append(labels, l)
+		}
+	}
+
+	return p, labels, true
+}
+
+func isSet(name string) func(getenv func(string) string) bool {
+	return func(getenv func(string) string) bool {
+		return getenv(name) != ""
+	}
+}
+
+// firstOf returns the first non-empty value of the named variables.
+func firstOf(getenv func(string) string, names ...string) string {
+	for _, n := range names {
+		if v := getenv(n); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// joinURL joins a base URL and path segments with single slashes, or returns "" if any part is empty.
+func joinURL(parts ...string) string {
+	for i, p := range parts {
+		if p == "" {
+			return ""
+		}
+		if i > 0 {
+			parts[i] = strings.Trim(p, "/")
+		}
+	}
+	parts[0] = strings.TrimRight(parts[0], "/")
+
+	return strings.Join(parts, "/")
+}
+
+func readGitHubActions(getenv func(string) string) CIMetadata {
+	repository := joinURL(getenv("GITHUB_SERVER_URL"), getenv("GITHUB_REPOSITORY"))
+
+	return CIMetadata{
+		Revision:    getenv("GITHUB_SHA"),
+		Source:      repository,
+		RefName:     firstOf(getenv, "GITHUB_HEAD_REF", "GITHUB_REF_NAME"),
+		RunURL:      joinURL(repository, "actions/runs", getenv("GITHUB_RUN_ID")),
+		BuildNumber: getenv("GITHUB_RUN_NUMBER"),
+	}
+}
+
+func readGitLabCI(getenv func(string) string) CIMetadata {
+	return CIMetadata{
+		Revision:    getenv("CI_COMMIT_SHA"),
+		Source:      getenv("CI_PROJECT_URL"),
+		RefName:     firstOf(getenv, "CI_COMMIT_TAG", "CI_COMMIT_REF_NAME"),
+		RunURL:      getenv("CI_PIPELINE_URL"),
+		BuildNumber: getenv("CI_PIPELINE_IID"),
+	}
+}
+
+func readJenkins(getenv func(string) string) CIMetadata {
+	ref := firstOf(getenv, "TAG_NAME", "BRANCH_NAME", "GIT_LOCAL_BRANCH", "GIT_BRANCH")
+
+	return CIMetadata{
+		Revision:    getenv("GIT_COMMIT"),
+		Source:      getenv("GIT_URL"),
+		RefName:     strings.TrimPrefix(ref, "origin/"),
+		RunURL:      getenv("BUILD_URL"),
+		BuildNumber: getenv("BUILD_NUMBER"),
+	}
+}
+
+func readCircleCI(getenv func(string) string) CIMetadata {
+	return CIMetadata{
+		Revision:    getenv("CIRCLE_SHA1"),
+		Source:      getenv("CIRCLE_REPOSITORY_URL"),
+		RefName:     firstOf(getenv, "CIRCLE_TAG", "CIRCLE_BRANCH"),
+		RunURL:      getenv("CIRCLE_BUILD_URL"),
+		BuildNumber: getenv("CIRCLE_BUILD_NUM"),
+	}
+}
+
+// readTekton reads the variables that a Tekton task passes to the build. Tekton does not set any variables itself, so
+// they are expected to be mapped from $(context.pipelineRun.name) and the results of a git-clone task. As pipeline
+// runs are not numbered, the name of the run is used as the build number.
+func readTekton(getenv func(string) string) CIMetadata {
+	return CIMetadata{
+		Revision:    getenv("TEKTON_GIT_COMMIT"),
+		Source:      getenv("TEKTON_GIT_URL"),
+		RefName:     getenv("TEKTON_GIT_REF"),
+		RunURL:      getenv("TEKTON_PIPELINE_RUN_URL"),
+		BuildNumber: getenv("TEKTON_PIPELINE_RUN"),
+	}
+}
+
+func readAzurePipelines(getenv func(string) string) CIMetadata {
+	ref := getenv("BUILD_SOURCEBRANCH")
+	for _, prefix := range []string{"refs/heads/", "refs/tags/"} {
+		ref = strings.TrimPrefix(ref, prefix)
+	}
+
+	var run string
+	if id := getenv("BUILD_BUILDID"); id != "" {
+		run = joinURL(getenv("SYSTEM_COLLECTIONURI"), getenv("SYSTEM_TEAMPROJECT"), "_build/results?buildId="+id)
+	}
+
+	return CIMetadata{
+		Revision:    getenv("BUILD_SOURCEVERSION"),
+		Source:      getenv("BUILD_REPOSITORY_URI"),
+		RefName:     ref,
+		RunURL:      run,
+		BuildNumber: getenv("BUILD_BUILDNUMBER"),
+	}
+}
+
+func readBuildkite(getenv func(string) string) CIMetadata {
+	return CIMetadata{
+		Revision:    getenv("BUILDKITE_COMMIT"),
+		Source:      getenv("BUILDKITE_REPO"),
+		RefName:     firstOf(getenv, "BUILDKITE_TAG", "BUILDKITE_BRANCH"),
+		RunURL:      getenv("BUILDKITE_BUILD_URL"),
+		BuildNumber: getenv("BUILDKITE_BUILD_NUMBER"),
+	}
+}
+
+func readBitbucketPipelines(getenv func(string) string) CIMetadata {
+	origin := getenv("BITBUCKET_GIT_HTTP_ORIGIN")
+
+	var run string
+	if origin != "" {
+		run = joinURL(origin, "addon/pipelines/home#!/results", getenv("BITBUCKET_BUILD_NUMBER"))
+	}
+
+	return CIMetadata{
+		Revision:    getenv("BITBUCKET_COMMIT"),
+		Source:      origin,
+		RefName:     firstOf(getenv, "BITBUCKET_TAG", "BITBUCKET_BRANCH"),
+		RunURL:      run,
+		BuildNumber: getenv("BITBUCKET_BUILD_NUMBER"),
+	}
+}
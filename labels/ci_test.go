@@ -0,0 +1,113 @@
+/*
+ * Copyright 2018-2025 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package labels_test
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/BurntSushi/toml"
+	. "github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+
+	"github.com/paketo-buildpacks/image-labels/v4/labels"
+)
+
+func testCI(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+	)
+
+	// fixture returns a getenv function for the environment in testdata/ci/<name>/environment, and the labels expected
+	// from it in testdata/ci/<name>/labels.toml.
+	fixture := func(name string) (func(string) string, map[string]string) {
+		f, err := os.Open(filepath.Join("testdata", "ci", name, "environment"))
+		Expect(err).NotTo(HaveOccurred())
+		defer f.Close()
+
+		env := make(map[string]string)
+		s := bufio.NewScanner(f)
+		for s.Scan() {
+			if k, v, ok := strings.Cut(s.Text(), "="); ok {
+				env[k] = v
+			}
+		}
+		Expect(s.Err()).NotTo(HaveOccurred())
+
+		expected := make(map[string]string)
+		_, err = toml.DecodeFile(filepath.Join("testdata", "ci", name, "labels.toml"), &expected)
+		Expect(err).NotTo(HaveOccurred())
+
+		return func(k string) string { return env[k] }, expected
+	}
+
+	for _, p := range labels.CIProviders {
+		it(fmt.Sprintf("reads %s", p.Name), func() {
+			getenv, expected := fixture(p.Name)
+
+			provider, l, ok := labels.CILabels(getenv)
+			Expect(ok).To(BeTrue())
+			Expect(provider.Name).To(Equal(p.Name))
+
+			m := make(map[string]string)
+			for _, l := range l {
+				m[l.Key] = l.Value
+			}
+			Expect(m).To(Equal(expected))
+
+			for _, l := range l {
+				Expect(labels.ValidateKey(l.Key)).To(BeEmpty())
+				Expect(labels.ValidateValue(l.Key, l.Value)).To(BeEmpty(), l.Key)
+			}
+		})
+	}
+
+	it("has a fixture for each provider only", func() {
+		dirs, err := os.ReadDir(filepath.Join("testdata", "ci"))
+		Expect(err).NotTo(HaveOccurred())
+
+		var names []string
+		for _, p := range labels.CIProviders {
+			names = append(names, p.Name)
+		}
+
+		for _, d := range dirs {
+			Expect(names).To(ContainElement(d.Name()))
+		}
+		Expect(dirs).To(HaveLen(len(names)))
+	})
+
+	it("detects nothing outside CI", func() {
+		_, l, ok := labels.CILabels(func(string) string { return "" })
+		Expect(ok).To(BeFalse())
+		Expect(l).To(BeEmpty())
+	})
+
+	it("omits labels without values", func() {
+		_, l, ok := labels.CILabels(func(k string) string {
+			return map[string]string{"GITHUB_ACTIONS": "true", "GITHUB_SHA": "abc123"}[k]
+		})
+		Expect(ok).To(BeTrue())
+		Expect(l).To(HaveLen(2))
+		Expect(l[0].Key).To(Equal("org.opencontainers.image.revision"))
+		Expect(l[1].Value).To(Equal("github-actions"))
+	})
+}
@@ -0,0 +1,147 @@
+/*
+ * Copyright 2018-2025 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package labels
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/buildpacks/libcnb/v2"
+	"github.com/paketo-buildpacks/libpak/v2"
+	"github.com/paketo-buildpacks/libpak/v2/log"
+)
+
+// ConflictPolicy decides which value is used when a label key is set more than once by the user.
+type ConflictPolicy string
+
+const (
+	// ConflictError fails the build.
+	ConflictError ConflictPolicy = "error"
+
+	// ConflictWarnLastWins uses the value set last and logs a warning. This is the default.
+	ConflictWarnLastWins ConflictPolicy = "warn-last-wins"
+
+	// ConflictFirstWins uses the value set first and logs the values that are ignored.
+	ConflictFirstWins ConflictPolicy = "first-wins"
+)
+
+// ResolveConflictPolicy returns the policy configured with $BP_IMAGE_LABELS_CONFLICTS.
+func ResolveConflictPolicy(cr libpak.ConfigurationResolver) (ConflictPolicy, error) {
+	s, ok := cr.Resolve("BP_IMAGE_LABELS_CONFLICTS")
+	if !ok || strings.TrimSpace(s) == "" {
+		return ConflictWarnLastWins, nil
+	}
+
+	switch p := ConflictPolicy(strings.ToLower(strings.TrimSpace(s))); p {
+	case ConflictError, ConflictWarnLastWins, ConflictFirstWins:
+		return p, nil
+	default:
+		return "", fmt.Errorf("unsupported $BP_IMAGE_LABELS_CONFLICTS %q, must be one of %s, %s or %s",
+			s, ConflictError, ConflictWarnLastWins, ConflictFirstWins)
+	}
+}
+
+// Collector gathers labels from several sources so that each key appears once, resolving keys that are set more
+// than once with a ConflictPolicy.
+type Collector struct {
+	Logger log.Logger
+	Policy ConflictPolicy
+
+	labels  []libcnb.Label
+	origins []Origin
+	index   map[string]int
+}
+
+// NewCollector creates a new Collector.
+func NewCollector(policy ConflictPolicy, logger log.Logger) *Collector {
+	return &Collector{Logger: logger, Policy: policy, index: make(map[string]int)}
+}
+
+// Add adds a label with the origin of its value. If the key has already been added with a different value, the policy decides
+// which value is kept and the outcome is logged. An error is returned only by the error policy.
+func (c *Collector) Add(key string, value string, origin Origin) error {
+	i, ok := c.index[key]
+	if !ok {
+		c.index[key] = len(c.labels)
+		c.labels = append(c.labels, libcnb.Label{Key: key, Value: value})
+		c.origins = append(c.origins, origin)
+		return nil
+	}
+
+	previous, previousOrigin := c.labels[i].Value, c.origins[i]
+	if previous == value {
+		return nil
+	}
+
+	switch c.Policy {
+	case ConflictError:
+		return fmt.Errorf("label %s is set to %q by %s and to %q by %s", key, previous, previousOrigin, value, origin)
+
+	case ConflictFirstWins:
+		c.Logger.Bodyf("WARNING: Label %s is set more than once, using %q from %s and ignoring %q from %s",
+			key, previous, previousOrigin, value, origin)
+
+	default:
+		c.Logger.Bodyf("WARNING: Label %s is set more than once, using %q from %s and ignoring %q from %s",
+			key, value, origin, previous, previousOrigin)
+		c.labels[i].Value, c.origins[i] = value, origin
+	}
+
+	return nil
+}
+
+// Has reports whether a key has been added.
+func (c *Collector) Has(key string) bool {
+	_, ok := c.index[key]
+	return ok
+}
+
+// Origin returns the origin of the value used for a key.
+func (c *Collector) Origin(key string) Origin {
+	if i, ok := c.index[key]; ok {
+		return c.origins[i]
+	}
+	return Origin{}
+}
+
+// Labels returns the labels sorted by key, so that the same labels are always returned in the same order whatever
+// order they were added in.
+func (c *Collector) Labels() []libcnb.Label {
+	if len(c.labels) == 0 {
+		return nil
+	}
+
+	labels := append([]libcnb.Label{}, c.labels...)
+	sort.Slice(labels, func(i, j int) bool {
+		return labels[i].Key < labels[j].Key
+	})
+	return labels
+}
+
+// Provenance returns the labels with the origins of their values, sorted by key.
+func (c *Collector) Provenance() []Provenance {
+	provenance := make([]Provenance, 0, len(c.labels))
+	for i, l := range c.labels {
+		provenance = append(provenance, Provenance{Key: l.Key, Value: l.Value, Origin: c.origins[i]})
+	}
+
+	sort.Slice(provenance, func(i, j int) bool {
+		return provenance[i].Key < provenance[j].Key
+	})
+	return provenance
+}
@@ -0,0 +1,129 @@
+/*
+ * Copyright 2018-2025 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package labels_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/buildpacks/libcnb/v2"
+	. "github.com/onsi/gomega"
+	"github.com/paketo-buildpacks/libpak/v2"
+	"github.com/paketo-buildpacks/libpak/v2/log"
+	"github.com/sclevine/spec"
+
+	"github.com/paketo-buildpacks/image-labels/v4/labels"
+)
+
+func testConflicts(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		buf    *bytes.Buffer
+		cr     libpak.ConfigurationResolver
+		logger log.Logger
+	)
+
+	it.Before(func() {
+		buf = &bytes.Buffer{}
+		logger = log.NewPaketoLogger(buf)
+	})
+
+	context("ResolveConflictPolicy", func() {
+		it("defaults to warn-last-wins", func() {
+			Expect(labels.ResolveConflictPolicy(cr)).To(Equal(labels.ConflictWarnLastWins))
+		})
+
+		it("resolves a policy", func() {
+			t.Setenv("BP_IMAGE_LABELS_CONFLICTS", "First-Wins")
+			Expect(labels.ResolveConflictPolicy(cr)).To(Equal(labels.ConflictFirstWins))
+		})
+
+		it("fails on an unknown policy", func() {
+			t.Setenv("BP_IMAGE_LABELS_CONFLICTS", "last-wins")
+
+			_, err := labels.ResolveConflictPolicy(cr)
+			Expect(err).To(MatchError(`unsupported $BP_IMAGE_LABELS_CONFLICTS "last-wins", must be one of error, warn-last-wins or first-wins`))
+		})
+	})
+
+	context("Collector", func() {
+		env := labels.Origin{Kind: labels.OriginEnvironment, Name: "BP_IMAGE_LABELS"}
+		file := labels.Origin{Kind: labels.OriginFile, Name: "labels.toml", Line: 3}
+
+		add := func(c *labels.Collector) error {
+			for _, l := range []struct {
+				key, value string
+				origin     labels.Origin
+			}{
+				{"alpha", "bravo", env},
+				{"charlie", "delta", file},
+				{"alpha", "bravo", file},
+				{"alpha", "echo", file},
+			} {
+				if err := c.Add(l.key, l.value, l.origin); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+
+		it("uses the last value with warn-last-wins", func() {
+			c := labels.NewCollector(labels.ConflictWarnLastWins, logger)
+
+			Expect(add(c)).To(Succeed())
+			Expect(c.Labels()).To(Equal([]libcnb.Label{{Key: "alpha", Value: "echo"}, {Key: "charlie", Value: "delta"}}))
+			Expect(c.Origin("alpha")).To(Equal(file))
+			Expect(buf.String()).To(ContainSubstring(`WARNING: Label alpha is set more than once, using "echo" from labels.toml:3 and ignoring "bravo" from $BP_IMAGE_LABELS`))
+		})
+
+		it("uses the first value with first-wins", func() {
+			c := labels.NewCollector(labels.ConflictFirstWins, logger)
+
+			Expect(add(c)).To(Succeed())
+			Expect(c.Labels()).To(Equal([]libcnb.Label{{Key: "alpha", Value: "bravo"}, {Key: "charlie", Value: "delta"}}))
+			Expect(c.Origin("alpha")).To(Equal(env))
+			Expect(buf.String()).To(ContainSubstring(`WARNING: Label alpha is set more than once, using "bravo" from $BP_IMAGE_LABELS and ignoring "echo" from labels.toml:3`))
+		})
+
+		it("fails with error", func() {
+			c := labels.NewCollector(labels.ConflictError, logger)
+
+			Expect(add(c)).To(MatchError(`label alpha is set to "bravo" by $BP_IMAGE_LABELS and to "echo" by labels.toml:3`))
+		})
+
+		it("ignores the same value set twice", func() {
+			c := labels.NewCollector(labels.ConflictError, logger)
+
+			Expect(c.Add("alpha", "bravo", env)).To(Succeed())
+			Expect(c.Add("alpha", "bravo", file)).To(Succeed())
+			Expect(c.Labels()).To(HaveLen(1))
+			Expect(buf.String()).To(BeEmpty())
+		})
+
+		it("returns the provenance of each label sorted by key", func() {
+			c := labels.NewCollector(labels.ConflictWarnLastWins, logger)
+
+			Expect(c.Add("charlie", "delta", file)).To(Succeed())
+			Expect(c.Add("alpha", "bravo", env)).To(Succeed())
+			Expect(c.Provenance()).To(Equal([]labels.Provenance{
+				{Key: "alpha", Value: "bravo", Origin: env},
+				{Key: "charlie", Value: "delta", Origin: file},
+			}))
+		})
+	})
+}
@@ -0,0 +1,72 @@
+/*
+ * Copyright 2018-2025 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package labels
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/paketo-buildpacks/libpak/v2"
+)
+
+// CreatedAuto is the value of $BP_OCI_CREATED that requests a generated timestamp.
+const CreatedAuto = "auto"
+
+// ResolveCreated returns an RFC 3339 timestamp, in UTC, for the org.opencontainers.image.created label along with a
+// description of where it came from.
+//
+// The timestamp is taken from $SOURCE_DATE_EPOCH if it is set, so that reproducible builds produce identical
+// images. Otherwise the committer time of HEAD is used if the application is a git repository, falling back to now.
+func ResolveCreated(applicationPath string, cr libpak.ConfigurationResolver, now time.Time) (string, string, error) {
+	if s, ok := cr.Resolve("SOURCE_DATE_EPOCH"); ok {
+		seconds, err := strconv.ParseInt(strings.TrimSpace(s), 10, 64)
+		if err != nil || seconds < 0 {
+			return "", "", fmt.Errorf("$SOURCE_DATE_EPOCH must be a non-negative integer number of seconds, found %q", s)
+		}
+
+		return formatCreated(time.Unix(seconds, 0)), "$SOURCE_DATE_EPOCH", nil
+	}
+
+	g, ok, err := NewGitRepository(applicationPath)
+	if err != nil {
+		return "", "", fmt.Errorf("unable to read git repository\n%w", err)
+	}
+
+	if ok {
+		m, err := g.Metadata()
+		if err != nil {
+			return "", "", fmt.Errorf("unable to read git metadata\n%w", err)
+		}
+
+		if m.Revision != "" {
+			t, err := g.CommitTime(m.Revision)
+			if err != nil {
+				return "", "", fmt.Errorf("unable to read commit time of %s\n%w", m.Revision, err)
+			}
+
+			return formatCreated(t), fmt.Sprintf("git commit %s", m.Revision), nil
+		}
+	}
+
+	return formatCreated(now), "current time", nil
+}
+
+func formatCreated(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,88 @@
+/*
+ * Copyright 2018-2025 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package labels_test
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/paketo-buildpacks/libpak/v2"
+	"github.com/sclevine/spec"
+
+	"github.com/paketo-buildpacks/image-labels/v4/labels"
+)
+
+func testCreated(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		cr  libpak.ConfigurationResolver
+		dir string
+		now = time.Date(2025, 3, 4, 5, 6, 7, 8, time.FixedZone("CET", 3600))
+	)
+
+	it.Before(func() {
+		dir = t.TempDir()
+	})
+
+	it("uses $SOURCE_DATE_EPOCH", func() {
+		t.Setenv("SOURCE_DATE_EPOCH", "1700000000")
+		writeLooseObject(t, filepath.Join(dir, ".git"), "commit", testCommitObject(1600000000))
+
+		created, source, err := labels.ResolveCreated(dir, cr, now)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(created).To(Equal("2023-11-14T22:13:20Z"))
+		Expect(source).To(Equal("$SOURCE_DATE_EPOCH"))
+	})
+
+	it("rejects an invalid $SOURCE_DATE_EPOCH", func() {
+		t.Setenv("SOURCE_DATE_EPOCH", "yesterday")
+
+		_, _, err := labels.ResolveCreated(dir, cr, now)
+		Expect(err).To(MatchError(ContainSubstring(`$SOURCE_DATE_EPOCH must be a non-negative integer number of seconds, found "yesterday"`)))
+	})
+
+	it("uses the commit time of HEAD", func() {
+		name := writeLooseObject(t, filepath.Join(dir, ".git"), "commit", testCommitObject(1600000000))
+		writeFiles(t, dir, map[string]string{
+			".git/HEAD":            "ref: refs/heads/main\n",
+			".git/refs/heads/main": name + "\n",
+		})
+
+		created, source, err := labels.ResolveCreated(dir, cr, now)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(created).To(Equal("2020-09-13T12:26:40Z"))
+		Expect(source).To(Equal("git commit " + name))
+	})
+
+	it("falls back to now in UTC", func() {
+		created, source, err := labels.ResolveCreated(dir, cr, now)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(created).To(Equal("2025-03-04T04:06:07Z"))
+		Expect(source).To(Equal("current time"))
+	})
+
+	it("falls back to now for an unborn branch", func() {
+		writeFiles(t, dir, map[string]string{".git/HEAD": "ref: refs/heads/main\n"})
+
+		created, _, err := labels.ResolveCreated(dir, cr, now)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(created).To(Equal("2025-03-04T04:06:07Z"))
+	})
+}
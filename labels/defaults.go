@@ -0,0 +1,87 @@
+/*
+ * Copyright 2018-2025 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package labels
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/buildpacks/libcnb/v2"
+)
+
+// DefaultLabelsMetadataKey is the key of the array of tables in the [metadata] of buildpack.toml that builder authors
+// can declare default labels in.
+const DefaultLabelsMetadataKey = "default-labels"
+
+// DefaultLabels returns the labels declared in the buildpack metadata as
+//
+//	[[metadata.default-labels]]
+//	key = "org.opencontainers.image.vendor"
+//	value = "Example Inc."
+//
+// in the order they are declared. Scalar values are converted to strings, and are not expanded.
+func DefaultLabels(metadata map[string]interface{}) ([]libcnb.Label, error) {
+	raw, ok := metadata[DefaultLabelsMetadataKey]
+	if !ok {
+		return nil, nil
+	}
+
+	var tables []map[string]interface{}
+	switch r := raw.(type) {
+	case []map[string]interface{}:
+		tables = r
+	case []interface{}:
+		for i, t := range r {
+			m, ok := t.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("unable to use a %T as default label %d", t, i)
+			}
+			tables = append(tables, m)
+		}
+	default:
+		return nil, fmt.Errorf("unable to use a %T as the default labels", raw)
+	}
+
+	labels := make([]libcnb.Label, 0, len(tables))
+	for i, t := range tables {
+		keys := make([]string, 0, len(t))
+		for k := range t {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			if k != "key" && k != "value" {
+				return nil, fmt.Errorf("unable to use %s in default label %d, only key and value are supported", k, i)
+			}
+		}
+
+		key, ok := t["key"].(string)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("unable to read default label %d, key must be a non-empty string", i)
+		}
+
+		m := make(map[string]string)
+		if err := flattenLabels("", map[string]interface{}{key: t["value"]}, m); err != nil {
+			return nil, fmt.Errorf("unable to read default label %d\n%w", i, err)
+		}
+
+		labels = append(labels, libcnb.Label{Key: key, Value: m[key]})
+	}
+
+	return labels, nil
+}
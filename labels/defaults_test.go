@@ -0,0 +1,91 @@
+/*
+ * Copyright 2018-2025 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package labels_test
+
+import (
+	"testing"
+
+	"github.com/BurntSushi/toml"
+	"github.com/buildpacks/libcnb/v2"
+	. "github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+
+	"github.com/paketo-buildpacks/image-labels/v4/labels"
+)
+
+func testDefaults(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+	)
+
+	it("has no default labels without metadata", func() {
+		Expect(labels.DefaultLabels(nil)).To(BeEmpty())
+	})
+
+	it("reads default labels from buildpack.toml", func() {
+		var bp struct {
+			Metadata map[string]interface{} `toml:"metadata"`
+		}
+		_, err := toml.Decode(`
+[[metadata.default-labels]]
+key = "org.opencontainers.image.vendor"
+value = "Example Inc."
+
+[[metadata.default-labels]]
+key = "com.example.support"
+value = "${SUPPORT_EMAIL:-support@example.com}"
+
+[[metadata.default-labels]]
+key = "com.example.tier"
+value = 1
+`, &bp)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(labels.DefaultLabels(bp.Metadata)).To(Equal([]libcnb.Label{
+			{Key: "org.opencontainers.image.vendor", Value: "Example Inc."},
+			{Key: "com.example.support", Value: "${SUPPORT_EMAIL:-support@example.com}"},
+			{Key: "com.example.tier", Value: "1"},
+		}))
+	})
+
+	it("reads default labels decoded as a list", func() {
+		Expect(labels.DefaultLabels(map[string]interface{}{
+			"default-labels": []interface{}{map[string]interface{}{"key": "alpha"}},
+		})).To(Equal([]libcnb.Label{{Key: "alpha", Value: ""}}))
+	})
+
+	it("fails without a key", func() {
+		_, err := labels.DefaultLabels(map[string]interface{}{
+			"default-labels": []map[string]interface{}{{"value": "bravo"}},
+		})
+		Expect(err).To(MatchError("unable to read default label 0, key must be a non-empty string"))
+	})
+
+	it("fails on unknown fields", func() {
+		_, err := labels.DefaultLabels(map[string]interface{}{
+			"default-labels": []map[string]interface{}{{"key": "alpha", "val": "bravo"}},
+		})
+		Expect(err).To(MatchError("unable to use val in default label 0, only key and value are supported"))
+	})
+
+	it("fails on a table", func() {
+		_, err := labels.DefaultLabels(map[string]interface{}{
+			"default-labels": map[string]interface{}{"alpha": "bravo"},
+		})
+		Expect(err).To(MatchError("unable to use a map[string]interface {} as the default labels"))
+	})
+}
@@ -18,6 +18,8 @@ package labels
 
 import (
 	"fmt"
+	"os"
+	"strings"
 
 	"github.com/paketo-buildpacks/libpak/v2/log"
 
@@ -47,6 +49,64 @@ func NewDetect(l log.Logger) libcnb.DetectFunc {
 		_, ok := cr.Resolve("BP_IMAGE_LABELS")
 		pass = pass || ok
 
+		// Malformed per-label variables fail the build, but must not abort detection for the whole group.
+		for _, e := range os.Environ() {
+			name, _, _ := strings.Cut(e, "=")
+			switch {
+			case !strings.HasPrefix(name, LabelVariablePrefix):
+			case IsLabelVariable(name):
+				pass = true
+			default:
+				l.Bodyf("Ignoring $%s, the name must be %s<n>_KEY, %s<n>_VALUE or %s_<key>",
+					name, LabelVariablePrefix, LabelVariablePrefix, LabelVariablePrefix)
+			}
+		}
+
+		pass = pass || cr.ResolveBool("BP_IMAGE_LABELS_AUTHORS")
+		pass = pass || cr.ResolveBool("BP_IMAGE_LABELS_CI")
+		pass = pass || cr.ResolveBool("BP_IMAGE_LABELS_GIT")
+		pass = pass || cr.ResolveBool("BP_IMAGE_LABELS_INFER")
+		pass = pass || cr.ResolveBool("BP_IMAGE_LABELS_LICENSE")
+		pass = pass || cr.ResolveBool("BP_IMAGE_LABELS_README")
+
+		// A label policy is enforced by the build, even if it is the only configuration.
+		_, ok = cr.Resolve("BP_IMAGE_LABELS_POLICY")
+		pass = pass || ok
+		_, ok = context.Buildpack.Metadata[PolicyMetadataKey]
+		pass = pass || ok
+
+		defaults, err := DefaultLabels(context.Buildpack.Metadata)
+		if err != nil {
+			return libcnb.DetectResult{}, fmt.Errorf("unable to read default labels\n%w", err)
+		}
+		pass = pass || len(defaults) > 0
+
+		_, ok, err = FindLabelsFile(context.ApplicationPath, cr)
+		if err != nil {
+			return libcnb.DetectResult{}, fmt.Errorf("unable to find labels file\n%w", err)
+		}
+		pass = pass || ok
+
+		_, ok, err = FindDockerfile(context.ApplicationPath, cr)
+		if err != nil {
+			return libcnb.DetectResult{}, fmt.Errorf("unable to find Dockerfile\n%w", err)
+		}
+		pass = pass || ok
+
+		if !pass && cr.ResolveBool("BP_IMAGE_LABELS_CONTRIBUTIONS") {
+			l.Body("No supported environment variables were set, providing image-labels for other buildpacks")
+			return libcnb.DetectResult{
+				Pass: true,
+				Plans: []libcnb.BuildPlan{
+					{
+						Provides: []libcnb.BuildPlanProvide{
+							{Name: PlanEntryName},
+						},
+					},
+				},
+			}, nil
+		}
+
 		if !pass {
 			l.Body("SKIPPED: No supported environment variables were set")
 			return libcnb.DetectResult{Pass: false}, nil
@@ -57,10 +117,10 @@ func NewDetect(l log.Logger) libcnb.DetectFunc {
 			Plans: []libcnb.BuildPlan{
 				{
 					Provides: []libcnb.BuildPlanProvide{
-						{Name: "image-labels"},
+						{Name: PlanEntryName},
 					},
 					Requires: []libcnb.BuildPlanRequire{
-						{Name: "image-labels"},
+						{Name: PlanEntryName},
 					},
 				},
 			},
@@ -19,6 +19,7 @@ package labels_test
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/buildpacks/libcnb/v2"
@@ -41,6 +42,21 @@ func testDetect(t *testing.T, context spec.G, it spec.S) {
 		Expect(labels.NewDetect(logger)(ctx)).To(Equal(libcnb.DetectResult{Pass: false}))
 	})
 
+	it("only provides image-labels for contributions without any interesting environment variables set", func() {
+		t.Setenv("BP_IMAGE_LABELS_CONTRIBUTIONS", "true")
+
+		Expect(labels.NewDetect(logger)(ctx)).To(Equal(libcnb.DetectResult{
+			Pass: true,
+			Plans: []libcnb.BuildPlan{
+				{
+					Provides: []libcnb.BuildPlanProvide{
+						{Name: "image-labels"},
+					},
+				},
+			},
+		}))
+	})
+
 	context("$BP_IMAGE_LABELS", func() {
 		it.Before(func() {
 			t.Setenv("BP_IMAGE_LABELS", "")
@@ -63,6 +79,188 @@ func testDetect(t *testing.T, context spec.G, it spec.S) {
 		})
 	})
 
+	context("per-label environment variables", func() {
+		it("passes with $BP_IMAGE_LABEL_<n>_KEY", func() {
+			t.Setenv("BP_IMAGE_LABEL_1_KEY", "com.example.team")
+
+			result, err := labels.NewDetect(logger)(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Plans[0].Requires).To(Equal([]libcnb.BuildPlanRequire{{Name: "image-labels"}}))
+		})
+
+		it("passes with $BP_IMAGE_LABEL__<key>", func() {
+			t.Setenv("BP_IMAGE_LABEL__com_example_team", "platform")
+
+			result, err := labels.NewDetect(logger)(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Plans[0].Requires).To(Equal([]libcnb.BuildPlanRequire{{Name: "image-labels"}}))
+		})
+
+		it("ignores other $BP_IMAGE_LABEL_* variables", func() {
+			t.Setenv("BP_IMAGE_LABEL_TEAM", "platform")
+
+			Expect(labels.NewDetect(logger)(ctx)).To(Equal(libcnb.DetectResult{Pass: false}))
+
+			t.Setenv("BP_IMAGE_LABEL__com_example_team", "platform")
+
+			result, err := labels.NewDetect(logger)(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Plans[0].Requires).To(Equal([]libcnb.BuildPlanRequire{{Name: "image-labels"}}))
+		})
+	})
+
+	context("labels file", func() {
+		it.Before(func() {
+			ctx.ApplicationPath = t.TempDir()
+			Expect(os.WriteFile(filepath.Join(ctx.ApplicationPath, "labels.yaml"), []byte("alpha: bravo\n"), 0644)).To(Succeed())
+		})
+
+		it.After(func() {
+			ctx.ApplicationPath = ""
+		})
+
+		it("passes with a labels file", func() {
+			Expect(labels.NewDetect(logger)(ctx)).To(Equal(libcnb.DetectResult{
+				Pass: true,
+				Plans: []libcnb.BuildPlan{
+					{
+						Provides: []libcnb.BuildPlanProvide{
+							{Name: "image-labels"},
+						},
+						Requires: []libcnb.BuildPlanRequire{
+							{Name: "image-labels"},
+						},
+					},
+				},
+			}))
+		})
+	})
+
+	context("Dockerfile", func() {
+		it.Before(func() {
+			ctx.ApplicationPath = t.TempDir()
+			Expect(os.WriteFile(filepath.Join(ctx.ApplicationPath, "Containerfile"), []byte("FROM scratch\n"), 0644)).To(Succeed())
+		})
+
+		it.After(func() {
+			ctx.ApplicationPath = ""
+		})
+
+		it("passes with a Dockerfile", func() {
+			result, err := labels.NewDetect(logger)(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Plans[0].Requires).To(Equal([]libcnb.BuildPlanRequire{{Name: "image-labels"}}))
+		})
+	})
+
+	context("$BP_IMAGE_LABELS_INFER", func() {
+		it("passes with $BP_IMAGE_LABELS_INFER", func() {
+			t.Setenv("BP_IMAGE_LABELS_INFER", "true")
+
+			result, err := labels.NewDetect(logger)(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Pass).To(BeTrue())
+		})
+	})
+
+	context("$BP_IMAGE_LABELS_LICENSE", func() {
+		it("passes with $BP_IMAGE_LABELS_LICENSE", func() {
+			t.Setenv("BP_IMAGE_LABELS_LICENSE", "true")
+
+			result, err := labels.NewDetect(logger)(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Pass).To(BeTrue())
+		})
+	})
+
+	context("$BP_IMAGE_LABELS_README", func() {
+		it("passes with $BP_IMAGE_LABELS_README", func() {
+			t.Setenv("BP_IMAGE_LABELS_README", "true")
+
+			result, err := labels.NewDetect(logger)(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Pass).To(BeTrue())
+		})
+	})
+
+	context("$BP_IMAGE_LABELS_AUTHORS", func() {
+		it("passes with $BP_IMAGE_LABELS_AUTHORS", func() {
+			t.Setenv("BP_IMAGE_LABELS_AUTHORS", "true")
+
+			result, err := labels.NewDetect(logger)(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Pass).To(BeTrue())
+		})
+	})
+
+	context("$BP_IMAGE_LABELS_CI", func() {
+		it("passes with $BP_IMAGE_LABELS_CI", func() {
+			t.Setenv("BP_IMAGE_LABELS_CI", "true")
+
+			result, err := labels.NewDetect(logger)(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Plans[0].Requires).To(Equal([]libcnb.BuildPlanRequire{{Name: "image-labels"}}))
+		})
+	})
+
+	context("$BP_IMAGE_LABELS_GIT", func() {
+		it("passes with $BP_IMAGE_LABELS_GIT", func() {
+			t.Setenv("BP_IMAGE_LABELS_GIT", "true")
+
+			result, err := labels.NewDetect(logger)(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Pass).To(BeTrue())
+		})
+
+		it("fails with $BP_IMAGE_LABELS_GIT disabled", func() {
+			t.Setenv("BP_IMAGE_LABELS_GIT", "false")
+
+			result, err := labels.NewDetect(logger)(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Pass).To(BeFalse())
+		})
+	})
+
+	context("default labels", func() {
+		it("passes with default labels in the buildpack metadata", func() {
+			ctx.Buildpack.Metadata = map[string]interface{}{
+				"default-labels": []map[string]interface{}{{"key": "org.opencontainers.image.vendor", "value": "Example Inc."}},
+			}
+			defer func() { ctx.Buildpack.Metadata = nil }()
+
+			result, err := labels.NewDetect(logger)(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Plans[0].Requires).To(Equal([]libcnb.BuildPlanRequire{{Name: "image-labels"}}))
+		})
+
+		it("fails on invalid default labels", func() {
+			ctx.Buildpack.Metadata = map[string]interface{}{"default-labels": "vendor=Example"}
+			defer func() { ctx.Buildpack.Metadata = nil }()
+
+			_, err := labels.NewDetect(logger)(ctx)
+			Expect(err).To(MatchError(ContainSubstring("unable to read default labels")))
+		})
+	})
+
+	context("label policy", func() {
+		it("passes with $BP_IMAGE_LABELS_POLICY", func() {
+			t.Setenv("BP_IMAGE_LABELS_POLICY", "policy.toml")
+
+			result, err := labels.NewDetect(logger)(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Plans[0].Requires).To(Equal([]libcnb.BuildPlanRequire{{Name: "image-labels"}}))
+		})
+
+		it("passes with a policy in the buildpack metadata", func() {
+			ctx.Buildpack.Metadata = map[string]interface{}{"label-policy": map[string]interface{}{"required": []interface{}{"alpha"}}}
+			defer func() { ctx.Buildpack.Metadata = nil }()
+
+			result, err := labels.NewDetect(logger)(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Plans[0].Requires).To(Equal([]libcnb.BuildPlanRequire{{Name: "image-labels"}}))
+		})
+	})
+
 	for k := range labels.Labels {
 		context(fmt.Sprintf("$%s", k), func() {
 			it.Before(func() {
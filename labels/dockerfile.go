@@ -0,0 +1,659 @@
+/*
+ * Copyright 2018-2025 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package labels
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/paketo-buildpacks/libpak/v2"
+)
+
+// DockerfileNames are the file names, in order of preference, searched for in the application directory when
+// $BP_IMAGE_LABELS_DOCKERFILE is not set.
+var DockerfileNames = []string{"Dockerfile", "Containerfile"}
+
+var (
+	dockerfileDirective = regexp.MustCompile(`^#\s*([a-zA-Z][a-zA-Z0-9]*)\s*=\s*(.*?)\s*$`)
+	dockerfileHeredoc   = regexp.MustCompile(`<<(-?)(?:"([^"]+)"|'([^']+)'|([A-Za-z_][A-Za-z0-9_.-]*))`)
+)
+
+// DockerfileLabel is a label set by a LABEL instruction of a Dockerfile.
+type DockerfileLabel struct {
+	Key   string
+	Value string
+
+	// Line is the line of the Dockerfile that the LABEL instruction starts on.
+	Line int
+
+	// Unresolved is the name of a variable referenced by the key or value whose value is only known when the image
+	// is built, such as an ARG without a default or an ENV of the base image. If it is set, Key is as written in the
+	// Dockerfile and Value is empty.
+	Unresolved string
+}
+
+// FindDockerfile returns the path to the Dockerfile of an application.
+//
+// If $BP_IMAGE_LABELS_DOCKERFILE is set, its value is resolved relative to the application path and returned whether
+// or not the file exists, so that a misconfiguration is reported at build time. Otherwise the first of
+// DockerfileNames that exists in the application path is returned. The boolean is false if no Dockerfile is
+// configured or found.
+func FindDockerfile(applicationPath string, cr libpak.ConfigurationResolver) (string, bool, error) {
+	if s, ok := cr.Resolve("BP_IMAGE_LABELS_DOCKERFILE"); ok && s != "" {
+		if !filepath.IsAbs(s) {
+			s = filepath.Join(applicationPath, s)
+		}
+		return s, true, nil
+	}
+
+	for _, name := range DockerfileNames {
+		file := filepath.Join(applicationPath, name)
+		if fi, err := os.Stat(file); err == nil && !fi.IsDir() {
+			return file, true, nil
+		} else if err != nil && !os.IsNotExist(err) {
+			return "", false, fmt.Errorf("unable to stat %s\n%w", file, err)
+		}
+	}
+
+	return "", false, nil
+}
+
+// ReadDockerfile reads the labels of the image built from the last stage of a Dockerfile, as described with
+// ParseDockerfile.
+func ReadDockerfile(path string) ([]DockerfileLabel, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read %s\n%w", path, err)
+	}
+
+	l, err := ParseDockerfile(string(b))
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse %s\n%w", path, err)
+	}
+	return l, nil
+}
+
+// ParseDockerfile returns the labels of the image built from the last stage of a Dockerfile, in the order they are
+// first set, without building it.
+//
+// Only the instructions that affect labels are read: FROM, ARG, ENV and LABEL. Both the key=value and the legacy
+// "LABEL key value" forms are supported, as are line continuations, comments, the escape parser directive, quotes
+// and escapes, and heredocs, whose bodies are skipped. Labels are inherited from an earlier stage that a stage is
+// built FROM, and a label set again replaces the earlier value.
+//
+// Variable references ($VAR, ${VAR}, ${VAR:-word}, ${VAR-word}, ${VAR:+word}, ${VAR+word}, ${VAR:?message} and
+// ${VAR?message}) are replaced by the values of ENV and ARG instructions, using the default of an ARG. A label that
+// references a variable whose value is only known when the image is built is returned with Unresolved set.
+func ParseDockerfile(text string) ([]DockerfileLabel, error) {
+	instructions, escape, err := dockerfileInstructions(text)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		global = make(map[string]*string)
+		stages []*dockerfileStage
+		stage  *dockerfileStage
+	)
+
+	for _, in := range instructions {
+		if err := func() error {
+			switch in.Keyword {
+			case "FROM":
+				stage = newDockerfileStage(in.Args, global, stages, escape)
+				stages = append(stages, stage)
+
+			case "ARG":
+				if stage == nil {
+					return dockerfileArgs(in.Args, global, global, nil, escape)
+				}
+				return dockerfileArgs(in.Args, stage.args, global, stage.lookup, escape)
+
+			case "ENV":
+				if stage == nil {
+					return nil
+				}
+
+				pairs, err := dockerfilePairs(in.Args, escape)
+				if err != nil {
+					return err
+				}
+
+				env := make(map[string]*string, len(pairs))
+				for _, p := range pairs {
+					key, err := dockerfileWord(p[0], escape, stage.lookup)
+					if errors.As(err, new(*dockerfileUnresolved)) {
+						continue
+					} else if err != nil {
+						return err
+					}
+
+					value, err := dockerfileWord(p[1], escape, stage.lookup)
+					if errors.As(err, new(*dockerfileUnresolved)) {
+						env[key] = nil
+						continue
+					} else if err != nil {
+						return err
+					}
+					env[key] = &value
+				}
+
+				// Every pair of an ENV instruction is expanded with the variables set before it.
+				for k, v := range env {
+					stage.env[k] = v
+				}
+
+			case "LABEL":
+				if stage == nil {
+					return nil
+				}
+
+				pairs, err := dockerfilePairs(in.Args, escape)
+				if err != nil {
+					return err
+				}
+
+				for _, p := range pairs {
+					l := DockerfileLabel{Line: in.Line}
+
+					var u *dockerfileUnresolved
+					if l.Key, err = dockerfileWord(p[0], escape, stage.lookup); errors.As(err, &u) {
+						l.Key, l.Unresolved = p[0], u.Name
+					} else if err != nil {
+						return err
+					} else if l.Value, err = dockerfileWord(p[1], escape, stage.lookup); errors.As(err, &u) {
+						l.Value, l.Unresolved = "", u.Name
+					} else if err != nil {
+						return err
+					}
+
+					stage.set(l)
+				}
+			}
+
+			return nil
+		}(); err != nil {
+			return nil, fmt.Errorf("unable to read %s instruction on line %d\n%w", in.Keyword, in.Line, err)
+		}
+	}
+
+	if stage == nil {
+		return nil, nil
+	}
+	return stage.labels, nil
+}
+
+// dockerfileInstruction is an instruction of a Dockerfile with its continuation lines joined.
+type dockerfileInstruction struct {
+	Keyword string
+	Args    string
+	Line    int
+}
+
+// dockerfileInstructions splits a Dockerfile into instructions, reading the parser directives at its start and
+// skipping comments, empty lines and the bodies of heredocs. It returns the instructions and the escape character.
+func dockerfileInstructions(text string) ([]dockerfileInstruction, byte, error) {
+	lines := strings.Split(strings.ReplaceAll(strings.TrimPrefix(text, "\uFEFF"), "\r\n", "\n"), "\n")
+
+	escape := byte('\\')
+	i := 0
+	for ; i < len(lines); i++ {
+		m := dockerfileDirective.FindStringSubmatch(lines[i])
+		if m == nil {
+			break
+		}
+
+		if strings.EqualFold(m[1], "escape") {
+			if m[2] != `\` && m[2] != "`" {
+				return nil, 0, fmt.Errorf("invalid escape directive %q on line %d, must be ` or \\", m[2], i+1)
+			}
+			escape = m[2][0]
+		}
+	}
+
+	var instructions []dockerfileInstruction
+	for ; i < len(lines); i++ {
+		s := strings.TrimLeft(lines[i], " \t")
+		if s == "" || s[0] == '#' {
+			continue
+		}
+
+		line := i + 1
+		for {
+			t := strings.TrimRight(s, " \t")
+			if t == "" || t[len(t)-1] != escape {
+				break
+			}
+			s = t[:len(t)-1]
+
+			// Comments and empty lines inside a continued instruction are skipped.
+			for i++; i < len(lines); i++ {
+				if t := strings.TrimLeft(lines[i], " \t"); t != "" && t[0] != '#' {
+					break
+				}
+			}
+			if i == len(lines) {
+				break
+			}
+			s += lines[i]
+		}
+
+		keyword, args := strings.TrimSpace(s), ""
+		if j := strings.IndexAny(keyword, " \t"); j >= 0 {
+			keyword, args = keyword[:j], keyword[j+1:]
+		}
+		in := dockerfileInstruction{Keyword: strings.ToUpper(keyword), Args: strings.TrimSpace(args), Line: line}
+		instructions = append(instructions, in)
+
+		if in.Keyword == "RUN" || in.Keyword == "COPY" || in.Keyword == "ADD" {
+			for _, m := range dockerfileHeredoc.FindAllStringSubmatch(strings.ReplaceAll(in.Args, "<<<", ""), -1) {
+				delimiter := m[2] + m[3] + m[4]
+				for i++; i < len(lines); i++ {
+					t := lines[i]
+					if m[1] == "-" {
+						t = strings.TrimLeft(t, "\t")
+					}
+					if t == delimiter {
+						break
+					}
+				}
+			}
+		}
+	}
+
+	return instructions, escape, nil
+}
+
+// dockerfileStage is the state of a build stage of a Dockerfile.
+type dockerfileStage struct {
+	name string
+
+	// scratch is true if the stage is based on scratch, whose image has no environment variables.
+	scratch bool
+
+	args   map[string]*string
+	env    map[string]*string
+	labels []DockerfileLabel
+}
+
+// newDockerfileStage creates a stage from the arguments of a FROM instruction, inheriting the environment variables
+// and labels of an earlier stage that it is based on.
+func newDockerfileStage(args string, global map[string]*string, stages []*dockerfileStage, escape byte) *dockerfileStage {
+	var fields []string
+	for _, f := range strings.Fields(args) {
+		if !strings.HasPrefix(f, "--") {
+			fields = append(fields, f)
+		}
+	}
+
+	s := &dockerfileStage{args: make(map[string]*string), env: make(map[string]*string)}
+	if len(fields) == 3 && strings.EqualFold(fields[1], "AS") {
+		s.name = strings.ToLower(fields[2])
+	}
+	if len(fields) == 0 {
+		return s
+	}
+
+	image, err := dockerfileWord(fields[0], escape, func(name string) (string, bool, error) {
+		if v, ok := global[name]; !ok {
+			return "", false, nil
+		} else if v == nil {
+			return "", false, &dockerfileUnresolved{Name: name}
+		} else {
+			return *v, true, nil
+		}
+	})
+	if err != nil {
+		return s
+	}
+
+	if strings.EqualFold(image, "scratch") {
+		s.scratch = true
+		return s
+	}
+
+	for i := len(stages) - 1; i >= 0; i-- {
+		if b := stages[i]; b.name != "" && b.name == strings.ToLower(image) {
+			s.scratch = b.scratch
+			for k, v := range b.env {
+				s.env[k] = v
+			}
+			s.labels = append(s.labels, b.labels...)
+			break
+		}
+	}
+
+	return s
+}
+
+// lookup returns the value of a variable in the stage, whether it is set and a *dockerfileUnresolved error if its
+// value is only known when the image is built. An ENV takes precedence over an ARG of the same name.
+func (s *dockerfileStage) lookup(name string) (string, bool, error) {
+	v, ok := s.env[name]
+	if !ok {
+		v, ok = s.args[name]
+	}
+
+	switch {
+	case ok && v != nil:
+		return *v, true, nil
+	case !ok && s.scratch:
+		return "", false, nil
+	default:
+		return "", false, &dockerfileUnresolved{Name: name}
+	}
+}
+
+// set sets a label in the stage, replacing the value of a label with the same key.
+func (s *dockerfileStage) set(l DockerfileLabel) {
+	for i := range s.labels {
+		if s.labels[i].Key == l.Key {
+			s.labels[i] = l
+			return
+		}
+	}
+	s.labels = append(s.labels, l)
+}
+
+// dockerfileArgs declares the variables of an ARG instruction in vars. A variable without a default takes the value
+// of the global ARG of the same name, if any, and is otherwise only known when the image is built.
+func dockerfileArgs(args string, vars map[string]*string, global map[string]*string,
+	lookup func(string) (string, bool, error), escape byte) error {
+
+	if lookup == nil {
+		lookup = func(name string) (string, bool, error) {
+			if v, ok := vars[name]; !ok {
+				return "", false, nil
+			} else if v == nil {
+				return "", false, &dockerfileUnresolved{Name: name}
+			} else {
+				return *v, true, nil
+			}
+		}
+	}
+
+	words, err := dockerfileWords(args, escape)
+	if err != nil {
+		return err
+	}
+
+	for _, w := range words {
+		name, def, ok := strings.Cut(w, "=")
+		if !ok {
+			if v, ok := global[name]; ok {
+				vars[name] = v
+			} else {
+				vars[name] = nil
+			}
+			continue
+		}
+
+		v, err := dockerfileWord(def, escape, lookup)
+		if errors.As(err, new(*dockerfileUnresolved)) {
+			vars[name] = nil
+			continue
+		} else if err != nil {
+			return err
+		}
+		vars[name] = &v
+	}
+
+	return nil
+}
+
+// dockerfilePairs splits the arguments of an ENV or LABEL instruction into unprocessed keys and values. If the first
+// word has no '=', the instruction has the legacy form of a key followed by a value that is the rest of the line.
+func dockerfilePairs(args string, escape byte) ([][2]string, error) {
+	words, err := dockerfileWords(args, escape)
+	if err != nil {
+		return nil, err
+	} else if len(words) == 0 {
+		return nil, fmt.Errorf("unable to find a key")
+	}
+
+	if !strings.Contains(words[0], "=") {
+		key, value, _ := strings.Cut(args, words[0])
+		if strings.TrimSpace(key) != "" || strings.TrimSpace(value) == "" {
+			return nil, fmt.Errorf("unable to find a value for %s", words[0])
+		}
+		return [][2]string{{words[0], strings.TrimSpace(value)}}, nil
+	}
+
+	pairs := make([][2]string, 0, len(words))
+	for _, w := range words {
+		key, value, ok := strings.Cut(w, "=")
+		if !ok {
+			return nil, fmt.Errorf("unable to find an equals sign after %s, must be of the form key=value", w)
+		}
+		pairs = append(pairs, [2]string{key, value})
+	}
+
+	return pairs, nil
+}
+
+// dockerfileWords splits arguments into words separated by whitespace outside of quotes, leaving the quotes and
+// escapes in place.
+func dockerfileWords(args string, escape byte) ([]string, error) {
+	var (
+		words []string
+		word  strings.Builder
+		quote byte
+	)
+
+	for i := 0; i < len(args); i++ {
+		c := args[i]
+		switch {
+		case quote == 0 && (c == ' ' || c == '\t' || c == '\n'):
+			if word.Len() > 0 {
+				words = append(words, word.String())
+				word.Reset()
+			}
+			continue
+		case c == escape && quote != '\'' && i+1 < len(args):
+			word.WriteByte(c)
+			i++
+			c = args[i]
+		case quote == 0 && (c == '"' || c == '\''):
+			quote = c
+		case c == quote:
+			quote = 0
+		}
+		word.WriteByte(c)
+	}
+
+	if quote != 0 {
+		return nil, ErrUnterminatedQuote
+	}
+	if word.Len() > 0 {
+		words = append(words, word.String())
+	}
+
+	return words, nil
+}
+
+// dockerfileUnresolved is returned by dockerfileWord for a reference to a variable whose value is only known when
+// the image is built.
+type dockerfileUnresolved struct {
+	Name string
+}
+
+func (e *dockerfileUnresolved) Error() string {
+	return fmt.Sprintf("variable %s is only known when the image is built", e.Name)
+}
+
+// dockerfileWord removes the quotes and escapes of a word and replaces its variable references with values from
+// lookup, as Docker does. Nothing is escaped or replaced inside single quotes, and inside double quotes only a
+// double quote, '$' or the escape character can be escaped.
+func dockerfileWord(word string, escape byte, lookup func(string) (string, bool, error)) (string, error) {
+	var b strings.Builder
+
+	for i := 0; i < len(word); {
+		switch c := word[i]; {
+		case c == '\'':
+			end := strings.IndexByte(word[i+1:], '\'')
+			if end < 0 {
+				return "", ErrUnterminatedQuote
+			}
+			b.WriteString(word[i+1 : i+1+end])
+			i += end + 2
+
+		case c == '"':
+			for i++; ; {
+				if i >= len(word) {
+					return "", ErrUnterminatedQuote
+				}
+
+				c := word[i]
+				if c == '"' {
+					i++
+					break
+				}
+
+				switch {
+				case c == escape && i+1 < len(word) && (word[i+1] == '"' || word[i+1] == '$' || word[i+1] == escape):
+					b.WriteByte(word[i+1])
+					i += 2
+				case c == '$':
+					v, n, err := dockerfileReference(word[i:], escape, lookup)
+					if err != nil {
+						return "", err
+					}
+					b.WriteString(v)
+					i += n
+				default:
+					b.WriteByte(c)
+					i++
+				}
+			}
+
+		case c == escape:
+			if i+1 < len(word) {
+				_, n := utf8.DecodeRuneInString(word[i+1:])
+				b.WriteString(word[i+1 : i+1+n])
+				i += n
+			}
+			i++
+
+		case c == '$':
+			v, n, err := dockerfileReference(word[i:], escape, lookup)
+			if err != nil {
+				return "", err
+			}
+			b.WriteString(v)
+			i += n
+
+		default:
+			b.WriteByte(c)
+			i++
+		}
+	}
+
+	return b.String(), nil
+}
+
+// dockerfileReference replaces the variable reference at the start of s, returning its value and the number of
+// bytes read. A '$' that does not start a reference is returned as is.
+func dockerfileReference(s string, escape byte, lookup func(string) (string, bool, error)) (string, int, error) {
+	if len(s) < 2 {
+		return "$", 1, nil
+	}
+
+	if s[1] != '{' {
+		n := 1
+		for n < len(s) && isVariableChar(s[n], n == 1) {
+			n++
+		}
+		if n == 1 {
+			return "$", 1, nil
+		}
+
+		v, _, err := lookup(s[1:n])
+		return v, n, err
+	}
+
+	end, err := closingBrace(s, 2)
+	if err != nil {
+		return "", 0, err
+	}
+	ref := s[2:end]
+
+	n := 0
+	for n < len(ref) && isVariableChar(ref[n], n == 0) {
+		n++
+	}
+	name, op := ref[:n], ref[n:]
+	if name == "" {
+		return "", 0, fmt.Errorf("invalid variable name in ${%s}", ref)
+	}
+
+	var word string
+	for _, o := range []string{":-", ":+", ":?", "-", "+", "?"} {
+		if strings.HasPrefix(op, o) {
+			op, word = o, op[len(o):]
+			break
+		}
+	}
+
+	value, set, err := lookup(name)
+	if err != nil {
+		return "", 0, err
+	}
+	empty := !set || (strings.HasPrefix(op, ":") && value == "")
+
+	switch op {
+	case "":
+		return value, end + 1, nil
+
+	case ":-", "-":
+		if empty {
+			value, err = dockerfileWord(word, escape, lookup)
+		}
+		return value, end + 1, err
+
+	case ":+", "+":
+		if empty {
+			return "", end + 1, nil
+		}
+		value, err = dockerfileWord(word, escape, lookup)
+		return value, end + 1, err
+
+	case ":?", "?":
+		if empty {
+			message, err := dockerfileWord(word, escape, lookup)
+			if err != nil {
+				return "", 0, err
+			}
+			if message == "" {
+				message = "parameter null or not set"
+			}
+			return "", 0, fmt.Errorf("variable %s is not set: %s", name, message)
+		}
+		return value, end + 1, nil
+
+	default:
+		return "", 0, fmt.Errorf("unsupported operator %q in ${%s}", op, ref)
+	}
+}
+
+func isVariableChar(c byte, first bool) bool {
+	return c == '_' || c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z' || !first && c >= '0' && c <= '9'
+}
@@ -0,0 +1,221 @@
+/*
+ * Copyright 2018-2025 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package labels_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/paketo-buildpacks/libpak/v2"
+	"github.com/sclevine/spec"
+
+	"github.com/paketo-buildpacks/image-labels/v4/labels"
+)
+
+func testDockerfile(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		cr libpak.ConfigurationResolver
+	)
+
+	context("FindDockerfile", func() {
+		it("finds nothing in an empty directory", func() {
+			_, ok, err := labels.FindDockerfile(t.TempDir(), cr)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ok).To(BeFalse())
+		})
+
+		it("prefers Dockerfile", func() {
+			dir := t.TempDir()
+			writeFiles(t, dir, map[string]string{"Containerfile": "", "Dockerfile": ""})
+
+			file, ok, err := labels.FindDockerfile(dir, cr)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ok).To(BeTrue())
+			Expect(file).To(Equal(filepath.Join(dir, "Dockerfile")))
+		})
+
+		it("finds Containerfile", func() {
+			dir := t.TempDir()
+			writeFiles(t, dir, map[string]string{"Containerfile": "", "Dockerfile/README.md": ""})
+
+			file, ok, err := labels.FindDockerfile(dir, cr)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ok).To(BeTrue())
+			Expect(file).To(Equal(filepath.Join(dir, "Containerfile")))
+		})
+
+		it("uses $BP_IMAGE_LABELS_DOCKERFILE relative to the application", func() {
+			t.Setenv("BP_IMAGE_LABELS_DOCKERFILE", "docker/app.Dockerfile")
+
+			dir := t.TempDir()
+			file, ok, err := labels.FindDockerfile(dir, cr)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ok).To(BeTrue())
+			Expect(file).To(Equal(filepath.Join(dir, "docker", "app.Dockerfile")))
+		})
+	})
+
+	context("ParseDockerfile", func() {
+		it("reads key=value and legacy forms", func() {
+			Expect(labels.ParseDockerfile(`FROM ubuntu:24.04
+LABEL org.opencontainers.image.title=my-app "com.example.team"="platform" empty=
+label com.example.description A great app
+`)).To(Equal([]labels.DockerfileLabel{
+				{Key: "org.opencontainers.image.title", Value: "my-app", Line: 2},
+				{Key: "com.example.team", Value: "platform", Line: 2},
+				{Key: "empty", Value: "", Line: 2},
+				{Key: "com.example.description", Value: "A great app", Line: 3},
+			}))
+		})
+
+		it("reads quotes and escapes", func() {
+			Expect(labels.ParseDockerfile(`FROM ubuntu:24.04
+LABEL double="a \"quoted\" \$value\n" single='it is $HOME\' unquoted=a\ b\=c
+`)).To(Equal([]labels.DockerfileLabel{
+				{Key: "double", Value: `a "quoted" $value\n`, Line: 2},
+				{Key: "single", Value: `it is $HOME\`, Line: 2},
+				{Key: "unquoted", Value: "a b=c", Line: 2},
+			}))
+		})
+
+		it("joins continuation lines, skipping comments", func() {
+			Expect(labels.ParseDockerfile(`# A comment
+FROM ubuntu:24.04
+
+LABEL alpha=bravo \
+# A comment inside the instruction
+
+      charlie="delta \
+echo"
+LABEL foxtrot=golf
+`)).To(Equal([]labels.DockerfileLabel{
+				{Key: "alpha", Value: "bravo", Line: 4},
+				{Key: "charlie", Value: "delta echo", Line: 4},
+				{Key: "foxtrot", Value: "golf", Line: 9},
+			}))
+		})
+
+		it("reads the escape directive", func() {
+			Expect(labels.ParseDockerfile("# escape=`\nFROM mcr.microsoft.com/windows/servercore\n" +
+				"LABEL path=\"C:\\Program Files\\app\" `\n    quote=\"a `\"b`\"\"\n")).
+				To(Equal([]labels.DockerfileLabel{
+					{Key: "path", Value: `C:\Program Files\app`, Line: 3},
+					{Key: "quote", Value: `a "b"`, Line: 3},
+				}))
+		})
+
+		it("replaces variables set by ARG and ENV", func() {
+			Expect(labels.ParseDockerfile(`ARG VERSION=1.2.3
+FROM ubuntu:24.04
+ARG VERSION
+ARG VENDOR="Example Corp."
+ENV APP=my-app
+ENV TITLE=${APP:-default} APP=other
+LABEL org.opencontainers.image.version=$VERSION \
+      org.opencontainers.image.vendor="${VENDOR}" \
+      org.opencontainers.image.title=$APP-${TITLE} \
+      com.example.set=${VERSION:+yes} \
+      '$VERSION'=literal
+`)).To(Equal([]labels.DockerfileLabel{
+				{Key: "org.opencontainers.image.version", Value: "1.2.3", Line: 7},
+				{Key: "org.opencontainers.image.vendor", Value: "Example Corp.", Line: 7},
+				{Key: "org.opencontainers.image.title", Value: "other-my-app", Line: 7},
+				{Key: "com.example.set", Value: "yes", Line: 7},
+				{Key: "$VERSION", Value: "literal", Line: 7},
+			}))
+		})
+
+		it("marks labels with variables only known when the image is built", func() {
+			Expect(labels.ParseDockerfile(`FROM ubuntu:24.04
+ARG REVISION
+ARG CREATED=${SOURCE_DATE_EPOCH}
+LABEL org.opencontainers.image.revision=$REVISION \
+      org.opencontainers.image.created=${CREATED} \
+      org.opencontainers.image.version=${JAVA_VERSION:-unknown} \
+      com.example.$REVISION=value \
+      org.opencontainers.image.title=my-app
+`)).To(Equal([]labels.DockerfileLabel{
+				{Key: "org.opencontainers.image.revision", Line: 4, Unresolved: "REVISION"},
+				{Key: "org.opencontainers.image.created", Line: 4, Unresolved: "CREATED"},
+				{Key: "org.opencontainers.image.version", Line: 4, Unresolved: "JAVA_VERSION"},
+				{Key: "com.example.$REVISION", Line: 4, Unresolved: "REVISION"},
+				{Key: "org.opencontainers.image.title", Value: "my-app", Line: 4},
+			}))
+		})
+
+		it("replaces undeclared variables with nothing in a stage from scratch", func() {
+			Expect(labels.ParseDockerfile("FROM scratch\nLABEL version=${VERSION:-unknown} home=$HOME\n")).
+				To(Equal([]labels.DockerfileLabel{
+					{Key: "version", Value: "unknown", Line: 2},
+					{Key: "home", Value: "", Line: 2},
+				}))
+		})
+
+		it("reads the labels of the last stage and the stages it is built from", func() {
+			Expect(labels.ParseDockerfile(`ARG BASE=base
+FROM --platform=$BUILDPLATFORM golang:1.24 AS build
+LABEL stage=build
+FROM ubuntu:24.04 AS base
+ENV TEAM=platform
+LABEL com.example.team=$TEAM stage=base
+FROM ${BASE}
+LABEL stage=final
+`)).To(Equal([]labels.DockerfileLabel{
+				{Key: "com.example.team", Value: "platform", Line: 6},
+				{Key: "stage", Value: "final", Line: 8},
+			}))
+		})
+
+		it("skips the bodies of heredocs", func() {
+			Expect(labels.ParseDockerfile(`FROM ubuntu:24.04
+RUN <<EOF
+LABEL shell=true
+EOF
+COPY <<-"FIRST" /first <<SECOND /second
+	LABEL first=true
+	FIRST
+LABEL second=true
+SECOND
+RUN cat <<< "LABEL here-string"
+LABEL docker=true
+`)).To(Equal([]labels.DockerfileLabel{
+				{Key: "docker", Value: "true", Line: 11},
+			}))
+		})
+
+		it("returns nothing without a stage", func() {
+			Expect(labels.ParseDockerfile("# Nothing\nLABEL alpha=bravo\n")).To(BeEmpty())
+		})
+
+		it("fails on invalid instructions", func() {
+			_, err := labels.ParseDockerfile("FROM ubuntu:24.04\nLABEL alpha=bravo charlie\n")
+			Expect(err).To(MatchError(ContainSubstring("unable to read LABEL instruction on line 2")))
+
+			_, err = labels.ParseDockerfile("FROM ubuntu:24.04\nLABEL alpha=\"bravo\n")
+			Expect(err).To(MatchError(ContainSubstring("unable to find a closing quote")))
+
+			_, err = labels.ParseDockerfile("FROM scratch\nLABEL alpha=\"${BRAVO:?must be set}\"\n")
+			Expect(err).To(MatchError(ContainSubstring("variable BRAVO is not set: must be set")))
+
+			_, err = labels.ParseDockerfile("# escape=x\nFROM scratch\n")
+			Expect(err).To(MatchError(ContainSubstring("invalid escape directive")))
+		})
+	})
+}
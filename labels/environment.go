@@ -0,0 +1,158 @@
+/*
+ * Copyright 2018-2025 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package labels
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// LabelVariablePrefix is the prefix of the environment variables that each set a single label.
+const LabelVariablePrefix = "BP_IMAGE_LABEL_"
+
+var indexedVariable = regexp.MustCompile(`^BP_IMAGE_LABEL_([0-9]+)_(KEY|VALUE)$`)
+
+// EnvironmentLabel is a label set by a per-label environment variable.
+type EnvironmentLabel struct {
+	Key   string
+	Value string
+
+	// Variable is the name of the environment variable that sets the value.
+	Variable string
+}
+
+// EnvironmentLabels returns the labels set by per-label environment variables, in either of two forms:
+//
+//	BP_IMAGE_LABEL_<n>_KEY=com.example.team
+//	BP_IMAGE_LABEL_<n>_VALUE=platform
+//
+//	BP_IMAGE_LABEL__com_example_team=platform
+//
+// In the first form n is any number, and the labels are returned in the order of their numbers. In the second form
+// the key is the rest of the name after the double underscore, read with KeyFromVariable, and the labels are
+// returned after those of the first form, sorted by variable name. Values are not expanded.
+func EnvironmentLabels(environ []string) ([]EnvironmentLabel, error) {
+	type indexed struct {
+		key, value       string
+		hasKey, hasValue bool
+	}
+
+	index := make(map[int]*indexed)
+	var named []EnvironmentLabel
+
+	for _, e := range environ {
+		name, value, _ := strings.Cut(e, "=")
+		if !strings.HasPrefix(name, LabelVariablePrefix) {
+			continue
+		}
+
+		if strings.HasPrefix(name, LabelVariablePrefix+"_") {
+			key, err := KeyFromVariable(name)
+			if err != nil {
+				return nil, err
+			}
+			named = append(named, EnvironmentLabel{Key: key, Value: value, Variable: name})
+			continue
+		}
+
+		m := indexedVariable.FindStringSubmatch(name)
+		if m == nil {
+			return nil, fmt.Errorf("unable to read $%s, the name must be %s<n>_KEY, %s<n>_VALUE or %s_<key>",
+				name, LabelVariablePrefix, LabelVariablePrefix, LabelVariablePrefix)
+		}
+
+		n, err := strconv.Atoi(m[1])
+		if err != nil {
+			return nil, fmt.Errorf("unable to read the index of $%s\n%w", name, err)
+		}
+
+		if index[n] == nil {
+			index[n] = &indexed{}
+		}
+		if m[2] == "KEY" {
+			index[n].key, index[n].hasKey = value, true
+		} else {
+			index[n].value, index[n].hasValue = value, true
+		}
+	}
+
+	numbers := make([]int, 0, len(index))
+	for n := range index {
+		numbers = append(numbers, n)
+	}
+	sort.Ints(numbers)
+
+	var labels []EnvironmentLabel
+	for _, n := range numbers {
+		i := index[n]
+		switch {
+		case !i.hasKey:
+			return nil, fmt.Errorf("unable to use $%s%d_VALUE without $%s%d_KEY", LabelVariablePrefix, n, LabelVariablePrefix, n)
+		case i.key == "":
+			return nil, fmt.Errorf("unable to have an empty key in $%s%d_KEY", LabelVariablePrefix, n)
+		}
+
+		labels = append(labels, EnvironmentLabel{Key: i.key, Value: i.value, Variable: fmt.Sprintf("%s%d_VALUE", LabelVariablePrefix, n)})
+	}
+
+	sort.Slice(named, func(i, j int) bool {
+		return named[i].Variable < named[j].Variable
+	})
+
+	return append(labels, named...), nil
+}
+
+// IsLabelVariable reports whether name is a per-label environment variable in either of the forms read by
+// EnvironmentLabels. Any other variable starting with LabelVariablePrefix is rejected by EnvironmentLabels.
+func IsLabelVariable(name string) bool {
+	return indexedVariable.MatchString(name) ||
+		(strings.HasPrefix(name, LabelVariablePrefix+"_") && len(name) > len(LabelVariablePrefix)+1)
+}
+
+// KeyFromVariable returns the key of a label named by an environment variable of the form BP_IMAGE_LABEL__<key>.
+// As environment variable names can only contain letters, digits and underscores, runs of underscores in the key
+// are read greedily, three at a time: "___" becomes '_', "__" becomes '-' and "_" becomes '.'. Letters keep their
+// case, so that BP_IMAGE_LABEL__com_example_cost__center___id is com.example.cost-center_id.
+func KeyFromVariable(name string) (string, error) {
+	s := strings.TrimPrefix(name, LabelVariablePrefix+"_")
+	if s == "" || s == name {
+		return "", fmt.Errorf("unable to read a key from $%s", name)
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(s); {
+		switch {
+		case strings.HasPrefix(s[i:], "___"):
+			b.WriteByte('_')
+			i += 3
+		case strings.HasPrefix(s[i:], "__"):
+			b.WriteByte('-')
+			i += 2
+		case s[i] == '_':
+			b.WriteByte('.')
+			i++
+		default:
+			b.WriteByte(s[i])
+			i++
+		}
+	}
+
+	return b.String(), nil
+}
@@ -0,0 +1,108 @@
+/*
+ * Copyright 2018-2025 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package labels_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+
+	"github.com/paketo-buildpacks/image-labels/v4/labels"
+)
+
+func testEnvironment(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+	)
+
+	context("EnvironmentLabels", func() {
+		it("ignores other variables", func() {
+			Expect(labels.EnvironmentLabels([]string{"BP_IMAGE_LABELS=alpha=bravo", "BP_IMAGE_LABELS_FILE=labels.toml", "PATH=/bin"})).To(BeEmpty())
+		})
+
+		it("reads indexed labels in order", func() {
+			Expect(labels.EnvironmentLabels([]string{
+				"BP_IMAGE_LABEL_10_VALUE=third value",
+				"BP_IMAGE_LABEL_2_KEY=com.example.team",
+				"BP_IMAGE_LABEL_10_KEY=com.example.description",
+				"BP_IMAGE_LABEL_2_VALUE=platform=infra",
+				"BP_IMAGE_LABEL_3_KEY=com.example.empty",
+			})).To(Equal([]labels.EnvironmentLabel{
+				{Key: "com.example.team", Value: "platform=infra", Variable: "BP_IMAGE_LABEL_2_VALUE"},
+				{Key: "com.example.empty", Value: "", Variable: "BP_IMAGE_LABEL_3_VALUE"},
+				{Key: "com.example.description", Value: "third value", Variable: "BP_IMAGE_LABEL_10_VALUE"},
+			}))
+		})
+
+		it("reads named labels after indexed labels", func() {
+			Expect(labels.EnvironmentLabels([]string{
+				"BP_IMAGE_LABEL__com_example_team=platform",
+				"BP_IMAGE_LABEL_1_KEY=alpha",
+				"BP_IMAGE_LABEL__com_example_cost__center=42",
+			})).To(Equal([]labels.EnvironmentLabel{
+				{Key: "alpha", Value: "", Variable: "BP_IMAGE_LABEL_1_VALUE"},
+				{Key: "com.example.cost-center", Value: "42", Variable: "BP_IMAGE_LABEL__com_example_cost__center"},
+				{Key: "com.example.team", Value: "platform", Variable: "BP_IMAGE_LABEL__com_example_team"},
+			}))
+		})
+
+		it("fails on a value without a key", func() {
+			_, err := labels.EnvironmentLabels([]string{"BP_IMAGE_LABEL_1_VALUE=bravo"})
+			Expect(err).To(MatchError("unable to use $BP_IMAGE_LABEL_1_VALUE without $BP_IMAGE_LABEL_1_KEY"))
+		})
+
+		it("fails on an empty key", func() {
+			_, err := labels.EnvironmentLabels([]string{"BP_IMAGE_LABEL_1_KEY=", "BP_IMAGE_LABEL_1_VALUE=bravo"})
+			Expect(err).To(MatchError("unable to have an empty key in $BP_IMAGE_LABEL_1_KEY"))
+		})
+
+		it("fails on other names", func() {
+			_, err := labels.EnvironmentLabels([]string{"BP_IMAGE_LABEL_TEAM=platform"})
+			Expect(err).To(MatchError("unable to read $BP_IMAGE_LABEL_TEAM, the name must be BP_IMAGE_LABEL_<n>_KEY, BP_IMAGE_LABEL_<n>_VALUE or BP_IMAGE_LABEL__<key>"))
+		})
+	})
+
+	context("IsLabelVariable", func() {
+		it("accepts either form", func() {
+			Expect(labels.IsLabelVariable("BP_IMAGE_LABEL_1_KEY")).To(BeTrue())
+			Expect(labels.IsLabelVariable("BP_IMAGE_LABEL_10_VALUE")).To(BeTrue())
+			Expect(labels.IsLabelVariable("BP_IMAGE_LABEL__com_example_team")).To(BeTrue())
+		})
+
+		it("rejects other names", func() {
+			Expect(labels.IsLabelVariable("BP_IMAGE_LABEL_TEAM")).To(BeFalse())
+			Expect(labels.IsLabelVariable("BP_IMAGE_LABEL_1_NAME")).To(BeFalse())
+			Expect(labels.IsLabelVariable("BP_IMAGE_LABEL__")).To(BeFalse())
+			Expect(labels.IsLabelVariable("BP_IMAGE_LABELS")).To(BeFalse())
+		})
+	})
+
+	context("KeyFromVariable", func() {
+		it("maps underscores", func() {
+			Expect(labels.KeyFromVariable("BP_IMAGE_LABEL__com_example_team")).To(Equal("com.example.team"))
+			Expect(labels.KeyFromVariable("BP_IMAGE_LABEL__com_example_cost__center___id")).To(Equal("com.example.cost-center_id"))
+			Expect(labels.KeyFromVariable("BP_IMAGE_LABEL__a____b")).To(Equal("a_.b"))
+			Expect(labels.KeyFromVariable("BP_IMAGE_LABEL__Team")).To(Equal("Team"))
+		})
+
+		it("fails without a key", func() {
+			_, err := labels.KeyFromVariable("BP_IMAGE_LABEL__")
+			Expect(err).To(MatchError("unable to read a key from $BP_IMAGE_LABEL__"))
+		})
+	})
+}
@@ -0,0 +1,97 @@
+/*
+ * Copyright 2018-2025 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package labels
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Unescape replaces the escape sequences in a key or value quoted with three double quotes.
+//
+// The supported sequences are
+//
+//	\\      a backslash
+//	\n      a newline
+//	\t      a tab
+//	\"      a double quote
+//	\'      a single quote
+//	\uXXXX  the unicode code point with the hexadecimal value XXXX
+//
+// A backslash followed by any other character, such as the \$ understood by Expand, is left as is.
+func Unescape(s string) (string, error) {
+	if !strings.Contains(s, `\`) {
+		return s, nil
+	}
+
+	var b strings.Builder
+
+	for i := 0; i < len(s); {
+		if s[i] != '\\' {
+			b.WriteByte(s[i])
+			i++
+			continue
+		}
+
+		v, n, err := readEscape(s, i)
+		if err != nil {
+			return "", err
+		}
+
+		b.WriteString(v)
+		i += n
+	}
+
+	return b.String(), nil
+}
+
+// readEscape reads the escape sequence starting with the backslash at s[i], returning its replacement and the number
+// of bytes read. An unsupported sequence is replaced by just the backslash, leaving the character after it to be read
+// normally.
+func readEscape(s string, i int) (string, int, error) {
+	if i+1 == len(s) {
+		return `\`, 1, nil
+	}
+
+	switch s[i+1] {
+	case '\\', '"', '\'':
+		return s[i+1 : i+2], 2, nil
+	case 'n':
+		return "\n", 2, nil
+	case 't':
+		return "\t", 2, nil
+	case 'u':
+		j := i + 2
+		for j < len(s) && j < i+6 && strings.IndexByte("0123456789abcdefABCDEF", s[j]) >= 0 {
+			j++
+		}
+
+		if j < i+6 {
+			return "", 0, fmt.Errorf("%w %q", ErrInvalidEscape, s[i:j])
+		}
+
+		r, err := strconv.ParseUint(s[i+2:j], 16, 32)
+		if err != nil {
+			return "", 0, fmt.Errorf("%w %q", ErrInvalidEscape, s[i:j])
+		}
+
+		return string(rune(r)), 6, nil
+	default:
+		return `\`, 1, nil
+	}
+}
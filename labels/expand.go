@@ -0,0 +1,142 @@
+/*
+ * Copyright 2018-2025 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package labels
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Expand replaces shell-style variable references in a string without running a shell.
+//
+// The supported forms are
+//
+//	${VAR}          the value of VAR, or an empty string if it is unset
+//	${VAR:-word}    the value of VAR, or word if it is unset or empty
+//	${VAR:?message} the value of VAR, or an error containing message if it is unset or empty
+//
+// word and message may themselves contain references. A '$' that is not followed by '{' is left as is, and '\$' is
+// replaced by a literal '$'.
+func Expand(s string, lookup func(string) (string, bool)) (string, error) {
+	var b strings.Builder
+
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '\\' && i+1 < len(s) && s[i+1] == '$':
+			b.WriteByte('$')
+			i++
+
+		case s[i] == '$' && i+1 < len(s) && s[i+1] == '{':
+			end, err := closingBrace(s, i+2)
+			if err != nil {
+				return "", err
+			}
+
+			v, err := expandReference(s[i+2:end], lookup)
+			if err != nil {
+				return "", err
+			}
+
+			b.WriteString(v)
+			i = end
+
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+
+	return b.String(), nil
+}
+
+// closingBrace returns the index of the '}' closing a reference whose content starts at start, skipping over nested
+// references and escaped characters.
+func closingBrace(s string, start int) (int, error) {
+	depth := 0
+	for i := start; i < len(s); i++ {
+		switch {
+		case s[i] == '\\' && i+1 < len(s):
+			i++
+		case s[i] == '$' && i+1 < len(s) && s[i+1] == '{':
+			depth++
+			i++
+		case s[i] == '}' && depth == 0:
+			return i, nil
+		case s[i] == '}':
+			depth--
+		}
+	}
+
+	return 0, fmt.Errorf("unable to find closing brace for ${%s", s[start:])
+}
+
+func expandReference(ref string, lookup func(string) (string, bool)) (string, error) {
+	name, op, word := ref, "", ""
+	if i := strings.Index(ref, ":"); i >= 0 {
+		name, op, word = ref[:i], ref[i:min(i+2, len(ref))], ref[min(i+2, len(ref)):]
+	}
+
+	if !isVariableName(name) {
+		return "", fmt.Errorf("invalid variable name %q in ${%s}", name, ref)
+	}
+
+	value, ok := lookup(name)
+
+	switch op {
+	case "":
+		return value, nil
+
+	case ":-":
+		if ok && value != "" {
+			return value, nil
+		}
+		return Expand(word, lookup)
+
+	case ":?":
+		if ok && value != "" {
+			return value, nil
+		}
+
+		message, err := Expand(word, lookup)
+		if err != nil {
+			return "", err
+		}
+		if message == "" {
+			message = "parameter null or not set"
+		}
+		return "", fmt.Errorf("variable %s is not set: %s", name, message)
+
+	default:
+		return "", fmt.Errorf("unsupported operator %q in ${%s}", op, ref)
+	}
+}
+
+func isVariableName(s string) bool {
+	if s == "" {
+		return false
+	}
+
+	for i, c := range s {
+		switch {
+		case c == '_', c >= 'A' && c <= 'Z', c >= 'a' && c <= 'z':
+		case c >= '0' && c <= '9' && i > 0:
+		default:
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,172 @@
+/*
+ * Copyright 2018-2025 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package labels
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/paketo-buildpacks/libpak/v2"
+	"go.yaml.in/yaml/v3"
+)
+
+// LabelsFiles are the file names, in order of preference, searched for in the application directory when
+// $BP_IMAGE_LABELS_FILE is not set.
+var LabelsFiles = []string{"labels.toml", "labels.yaml", "labels.yml", "labels.json"}
+
+// FindLabelsFile returns the path to the labels file for an application.
+//
+// If $BP_IMAGE_LABELS_FILE is set, its value is resolved relative to the application path and returned whether or
+// not the file exists, so that a misconfiguration is reported at build time. Otherwise the first of LabelsFiles that
+// exists in the application path is returned. The boolean is false if no labels file is configured or found.
+func FindLabelsFile(applicationPath string, cr libpak.ConfigurationResolver) (string, bool, error) {
+	if s, ok := cr.Resolve("BP_IMAGE_LABELS_FILE"); ok && s != "" {
+		if !filepath.IsAbs(s) {
+			s = filepath.Join(applicationPath, s)
+		}
+		return s, true, nil
+	}
+
+	for _, name := range LabelsFiles {
+		file := filepath.Join(applicationPath, name)
+		if _, err := os.Stat(file); err == nil {
+			return file, true, nil
+		} else if !os.IsNotExist(err) {
+			return "", false, fmt.Errorf("unable to stat %s\n%w", file, err)
+		}
+	}
+
+	return "", false, nil
+}
+
+// ReadLabelsFile reads labels from a TOML, YAML or JSON file, chosen by the file extension.
+//
+// The file must contain a single table of key/value pairs. Nested tables are flattened by joining their keys with
+// a '.', so that unquoted TOML keys such as org.opencontainers.image.title work as expected. Scalar values are
+// converted to strings; lists are rejected.
+func ReadLabelsFile(path string) (map[string]string, error) {
+	m, _, err := ReadLabelsFileLines(path)
+	return m, err
+}
+
+// ReadLabelsFileLines reads labels from a file as ReadLabelsFile does, and also returns the line of the file that
+// each label is set on. A label whose line cannot be determined is missing from the lines.
+func ReadLabelsFileLines(path string) (map[string]string, map[string]int, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to read %s\n%w", path, err)
+	}
+
+	var lines map[string]int
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".toml":
+		lines = tomlLines(b)
+	case ".yaml", ".yml":
+		lines = yamlLines(b)
+	case ".json":
+		lines = jsonLines(b)
+	default:
+		return nil, nil, fmt.Errorf("unsupported labels file extension %q for %s", ext, path)
+	}
+
+	raw := map[string]interface{}{}
+	if err := decodeFile(path, b, &raw); err != nil {
+		return nil, nil, err
+	}
+
+	m := make(map[string]string)
+	if err := flattenLabels("", raw, m); err != nil {
+		return nil, nil, fmt.Errorf("unable to read labels from %s\n%w", path, err)
+	}
+
+	// Keys of inline tables are not found on their own, so fall back to the line of the closest enclosing key.
+	found := make(map[string]int)
+	for k := range m {
+		for p := k; p != ""; {
+			if n, ok := lines[p]; ok {
+				found[k] = n
+				break
+			}
+
+			i := strings.LastIndex(p, ".")
+			if i < 0 {
+				break
+			}
+			p = p[:i]
+		}
+	}
+
+	return m, found, nil
+}
+
+// decodeFile decodes the contents of a TOML, YAML or JSON file, chosen by the file extension, into v.
+func decodeFile(path string, b []byte, v interface{}) error {
+	var err error
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".toml":
+		err = toml.Unmarshal(b, v)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(b, v)
+	case ".json":
+		err = json.Unmarshal(b, v)
+	default:
+		return fmt.Errorf("unsupported file extension %q for %s", ext, path)
+	}
+
+	if err != nil {
+		return fmt.Errorf("unable to decode %s\n%w", path, err)
+	}
+	return nil
+}
+
+func flattenLabels(prefix string, raw map[string]interface{}, m map[string]string) error {
+	keys := make([]string, 0, len(raw))
+	for k := range raw {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		key := k
+		if prefix != "" {
+			key = prefix + "." + k
+		}
+
+		switch v := raw[k].(type) {
+		case map[string]interface{}:
+			if err := flattenLabels(key, v, m); err != nil {
+				return err
+			}
+		case []interface{}, []map[string]interface{}:
+			return fmt.Errorf("unable to use a list as the value of %s", key)
+		case nil:
+			m[key] = ""
+		case time.Time:
+			m[key] = v.Format(time.RFC3339Nano)
+		default:
+			m[key] = fmt.Sprint(v)
+		}
+	}
+
+	return nil
+}
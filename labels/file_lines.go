@@ -0,0 +1,182 @@
+/*
+ * Copyright 2018-2025 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package labels
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"go.yaml.in/yaml/v3"
+)
+
+// tomlLines returns the line of each table header and key/value pair in a TOML document, keyed by the flattened
+// key. The decoder does not expose positions, so the document is read line by line, skipping multi-line strings,
+// and each key is normalized by decoding it on its own.
+func tomlLines(b []byte) map[string]int {
+	lines := make(map[string]int)
+
+	var table []string
+	var multiline string
+	for i, line := range strings.Split(string(b), "\n") {
+		if multiline != "" {
+			if strings.Count(line, multiline)%2 == 1 {
+				multiline = ""
+			}
+			continue
+		}
+
+		s := strings.TrimSpace(line)
+		switch {
+		case s == "" || s[0] == '#' || strings.HasPrefix(s, "[["):
+			continue
+
+		case s[0] == '[':
+			end := indexUnquoted(s, ']')
+			if end < 0 {
+				continue
+			}
+			table = tomlKey(s[1:end])
+			lines[strings.Join(table, ".")] = i + 1
+
+		default:
+			eq := indexUnquoted(s, '=')
+			if eq < 0 {
+				continue
+			}
+			key := append(append([]string{}, table...), tomlKey(s[:eq])...)
+			lines[strings.Join(key, ".")] = i + 1
+
+			for _, d := range []string{`"""`, `'''`} {
+				if strings.Count(s[eq+1:], d)%2 == 1 {
+					multiline = d
+					break
+				}
+			}
+		}
+	}
+
+	return lines
+}
+
+// tomlKey returns the parts of a possibly dotted and quoted TOML key, or nil if it is not a valid key.
+func tomlKey(s string) []string {
+	var v map[string]interface{}
+	md, err := toml.Decode(s+" = 0", &v)
+	if err != nil || len(md.Keys()) == 0 {
+		return nil
+	}
+
+	keys := md.Keys()
+	return keys[len(keys)-1]
+}
+
+// indexUnquoted returns the index of the first c in s that is not inside a TOML string, or -1.
+func indexUnquoted(s string, c byte) int {
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		switch {
+		case quote == '"' && s[i] == '\\':
+			i++
+		case quote != 0:
+			if s[i] == quote {
+				quote = 0
+			}
+		case s[i] == '"' || s[i] == '\'':
+			quote = s[i]
+		case s[i] == c:
+			return i
+		}
+	}
+
+	return -1
+}
+
+// yamlLines returns the line of each key in a YAML document, keyed by the flattened key.
+func yamlLines(b []byte) map[string]int {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(b, &doc); err != nil || len(doc.Content) == 0 {
+		return nil
+	}
+
+	lines := make(map[string]int)
+
+	var walk func(prefix string, n *yaml.Node)
+	walk = func(prefix string, n *yaml.Node) {
+		if n.Kind != yaml.MappingNode {
+			return
+		}
+
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			key := n.Content[i].Value
+			if prefix != "" {
+				key = prefix + "." + key
+			}
+
+			lines[key] = n.Content[i].Line
+			walk(key, n.Content[i+1])
+		}
+	}
+	walk("", doc.Content[0])
+
+	return lines
+}
+
+// jsonLines returns the line of each key in a JSON document, keyed by the flattened key.
+func jsonLines(b []byte) map[string]int {
+	lines := make(map[string]int)
+	d := json.NewDecoder(bytes.NewReader(b))
+
+	var walk func(prefix string) error
+	walk = func(prefix string) error {
+		for d.More() {
+			t, err := d.Token()
+			if err != nil {
+				return err
+			}
+
+			key, _ := t.(string)
+			if prefix != "" {
+				key = prefix + "." + key
+			}
+			lines[key] = 1 + bytes.Count(b[:d.InputOffset()], []byte("\n"))
+
+			if t, err = d.Token(); err != nil {
+				return err
+			}
+
+			switch t {
+			case json.Delim('{'):
+				if err := walk(key); err != nil {
+					return err
+				}
+			case json.Delim('['):
+				return nil
+			}
+		}
+
+		_, err := d.Token()
+		return err
+	}
+
+	if t, err := d.Token(); err == nil && t == json.Delim('{') {
+		_ = walk("")
+	}
+
+	return lines
+}
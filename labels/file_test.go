@@ -0,0 +1,203 @@
+/*
+ * Copyright 2018-2025 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package labels_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/paketo-buildpacks/libpak/v2"
+	"github.com/sclevine/spec"
+
+	"github.com/paketo-buildpacks/image-labels/v4/labels"
+)
+
+func testFile(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		cr  libpak.ConfigurationResolver
+		dir string
+	)
+
+	it.Before(func() {
+		dir = t.TempDir()
+	})
+
+	context("FindLabelsFile", func() {
+		it("finds nothing in an empty directory", func() {
+			_, ok, err := labels.FindLabelsFile(dir, cr)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ok).To(BeFalse())
+		})
+
+		it("prefers labels.toml", func() {
+			Expect(os.WriteFile(filepath.Join(dir, "labels.json"), []byte("{}"), 0644)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(dir, "labels.toml"), []byte(""), 0644)).To(Succeed())
+
+			file, ok, err := labels.FindLabelsFile(dir, cr)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ok).To(BeTrue())
+			Expect(file).To(Equal(filepath.Join(dir, "labels.toml")))
+		})
+
+		it("uses $BP_IMAGE_LABELS_FILE relative to the application", func() {
+			t.Setenv("BP_IMAGE_LABELS_FILE", "config/image.yaml")
+
+			file, ok, err := labels.FindLabelsFile(dir, cr)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ok).To(BeTrue())
+			Expect(file).To(Equal(filepath.Join(dir, "config", "image.yaml")))
+		})
+
+		it("uses an absolute $BP_IMAGE_LABELS_FILE", func() {
+			t.Setenv("BP_IMAGE_LABELS_FILE", "/etc/labels.json")
+
+			file, ok, err := labels.FindLabelsFile(dir, cr)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ok).To(BeTrue())
+			Expect(file).To(Equal("/etc/labels.json"))
+		})
+	})
+
+	context("ReadLabelsFile", func() {
+		it("reads TOML", func() {
+			file := filepath.Join(dir, "labels.toml")
+			Expect(os.WriteFile(file, []byte(`
+"com.example.team" = "platform"
+org.opencontainers.image.title = "my app"
+count = 3
+
+[com.example.support]
+email = "support@example.com"
+`), 0644)).To(Succeed())
+
+			Expect(labels.ReadLabelsFile(file)).To(Equal(map[string]string{
+				"com.example.team":               "platform",
+				"org.opencontainers.image.title": "my app",
+				"count":                          "3",
+				"com.example.support.email":      "support@example.com",
+			}))
+		})
+
+		it("reads YAML", func() {
+			file := filepath.Join(dir, "labels.yml")
+			Expect(os.WriteFile(file, []byte(`
+com.example.team: platform
+org.opencontainers.image:
+  title: my app
+`), 0644)).To(Succeed())
+
+			Expect(labels.ReadLabelsFile(file)).To(Equal(map[string]string{
+				"com.example.team":               "platform",
+				"org.opencontainers.image.title": "my app",
+			}))
+		})
+
+		it("reads JSON", func() {
+			file := filepath.Join(dir, "labels.json")
+			Expect(os.WriteFile(file, []byte(`{"com.example.team": "platform", "enabled": true}`), 0644)).To(Succeed())
+
+			Expect(labels.ReadLabelsFile(file)).To(Equal(map[string]string{
+				"com.example.team": "platform",
+				"enabled":          "true",
+			}))
+		})
+
+		it("rejects lists", func() {
+			file := filepath.Join(dir, "labels.json")
+			Expect(os.WriteFile(file, []byte(`{"tags": ["a", "b"]}`), 0644)).To(Succeed())
+
+			_, err := labels.ReadLabelsFile(file)
+			Expect(err).To(MatchError(ContainSubstring("unable to use a list as the value of tags")))
+		})
+
+		it("rejects unknown extensions", func() {
+			file := filepath.Join(dir, "labels.ini")
+			Expect(os.WriteFile(file, []byte(""), 0644)).To(Succeed())
+
+			_, err := labels.ReadLabelsFile(file)
+			Expect(err).To(MatchError(ContainSubstring(`unsupported labels file extension ".ini"`)))
+		})
+
+		it("fails on a missing file", func() {
+			_, err := labels.ReadLabelsFile(filepath.Join(dir, "labels.toml"))
+			Expect(err).To(MatchError(ContainSubstring("unable to read")))
+		})
+	})
+	context("ReadLabelsFileLines", func() {
+		it("finds the lines of TOML keys", func() {
+			file := filepath.Join(dir, "labels.toml")
+			Expect(os.WriteFile(file, []byte(`# labels
+"com.example.team" = "platform"
+description = """
+title = "not a key"
+"""
+org.opencontainers.image.title = "my app"
+links = { docs = "https://example.com/docs" }
+
+[com.example."support.desk"]
+email = "support@example.com" # = not a key either
+`), 0644)).To(Succeed())
+
+			_, lines, err := labels.ReadLabelsFileLines(file)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(lines).To(Equal(map[string]int{
+				"com.example.team":               2,
+				"description":                    3,
+				"org.opencontainers.image.title": 6,
+				"links.docs":                     7,
+				"com.example.support.desk.email": 10,
+			}))
+		})
+
+		it("finds the lines of YAML keys", func() {
+			file := filepath.Join(dir, "labels.yaml")
+			Expect(os.WriteFile(file, []byte(`com.example.team: platform
+org.opencontainers.image:
+  title: my app
+`), 0644)).To(Succeed())
+
+			_, lines, err := labels.ReadLabelsFileLines(file)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(lines).To(Equal(map[string]int{
+				"com.example.team":               1,
+				"org.opencontainers.image.title": 3,
+			}))
+		})
+
+		it("finds the lines of JSON keys", func() {
+			file := filepath.Join(dir, "labels.json")
+			Expect(os.WriteFile(file, []byte(`{
+  "com.example.team": "platform",
+  "org.opencontainers.image": {
+    "title": "my app"
+  }
+}
+`), 0644)).To(Succeed())
+
+			_, lines, err := labels.ReadLabelsFileLines(file)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(lines).To(Equal(map[string]int{
+				"com.example.team":               2,
+				"org.opencontainers.image.title": 4,
+			}))
+		})
+	})
+}
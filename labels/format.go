@@ -0,0 +1,118 @@
+/*
+ * Copyright 2018-2025 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package labels
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// FormatLabels returns labels in the $BP_IMAGE_LABELS syntax, as key=value pairs separated by single spaces, sorted by
+// key. Keys are quoted with QuoteKey and values with QuoteValue, so that ParseLabels returns the same map for any
+// labels without an empty key, which the syntax cannot represent.
+func FormatLabels(labels map[string]string) string {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, k := range keys {
+		pairs = append(pairs, fmt.Sprintf("%s=%s", QuoteKey(k), QuoteValue(labels[k])))
+	}
+
+	return strings.Join(pairs, " ")
+}
+
+// QuoteKey returns a key in the $BP_IMAGE_LABELS syntax. Keys made up of printable ASCII characters other than
+// whitespace, quotes, '\', '$' and '=' are returned as is, unless they start with a '{' that would be read as JSON,
+// and all others are quoted as described with QuoteValue.
+func QuoteKey(key string) string {
+	if isBare(key, `=`) && key[0] != '{' {
+		return key
+	}
+	return quote(key, true)
+}
+
+// QuoteValue returns a value in the $BP_IMAGE_LABELS syntax. Non-empty values made up of printable ASCII characters
+// other than whitespace, quotes, '\' and '$' are returned as is. All others are double quoted, or quoted with three
+// double quotes and escape sequences if they contain control characters or end with a backslash, which double quotes
+// cannot represent. Every '$' is escaped so that the value is not changed by Expand.
+func QuoteValue(value string) string {
+	if isBare(value, "") {
+		return value
+	}
+	return quote(value, false)
+}
+
+func isBare(s string, reserved string) bool {
+	if s == "" {
+		return false
+	}
+
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c <= ' ' || c >= 0x7f || strings.IndexByte(`"'\$`+reserved, c) >= 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+func quote(s string, key bool) string {
+	triple := strings.HasSuffix(s, `\`)
+	for i := 0; i < len(s); i++ {
+		if s[i] < ' ' || s[i] == 0x7f {
+			triple = true
+		}
+	}
+
+	delimiter := `"`
+	if triple {
+		delimiter = `"""`
+	}
+
+	var b strings.Builder
+	b.WriteString(delimiter)
+
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '\\' && triple:
+			b.WriteString(`\\`)
+		case c == '"':
+			b.WriteString(`\"`)
+		case c == '\'':
+			b.WriteString(`\'`)
+		case c == '\n':
+			b.WriteString(`\n`)
+		case c == '\t':
+			b.WriteString(`\t`)
+		case c == '$' && !key:
+			b.WriteString(`\$`)
+		case c < ' ' || c == 0x7f:
+			fmt.Fprintf(&b, `\u%04x`, c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+
+	b.WriteString(delimiter)
+	return b.String()
+}
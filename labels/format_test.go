@@ -0,0 +1,130 @@
+/*
+ * Copyright 2018-2025 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package labels_test
+
+import (
+	"reflect"
+	"testing"
+	"testing/quick"
+
+	. "github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+
+	"github.com/paketo-buildpacks/image-labels/v4/labels"
+)
+
+func testFormat(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+	)
+
+	context("QuoteValue", func() {
+		it("leaves simple values bare", func() {
+			Expect(labels.QuoteValue("bravo")).To(Equal("bravo"))
+			Expect(labels.QuoteValue("https://example.com/a?b=c")).To(Equal("https://example.com/a?b=c"))
+		})
+
+		it("quotes other values", func() {
+			Expect(labels.QuoteValue("")).To(Equal(`""`))
+			Expect(labels.QuoteValue("delta echo")).To(Equal(`"delta echo"`))
+			Expect(labels.QuoteValue(`it's "quoted"`)).To(Equal(`"it\'s \"quoted\""`))
+			Expect(labels.QuoteValue(`C:\Program Files`)).To(Equal(`"C:\Program Files"`))
+			Expect(labels.QuoteValue("snowman ☃")).To(Equal(`"snowman ☃"`))
+		})
+
+		it("quotes values that double quotes cannot represent with three double quotes", func() {
+			Expect(labels.QuoteValue("C:\\Users\n\tnext")).To(Equal(`"""C:\\Users\n\tnext"""`))
+			Expect(labels.QuoteValue("bell\a")).To(Equal(`"""bell\u0007"""`))
+			Expect(labels.QuoteValue(`C:\`)).To(Equal(`"""C:\\"""`))
+		})
+
+		it("escapes variable references", func() {
+			Expect(labels.QuoteValue("${HOME}")).To(Equal(`"\${HOME}"`))
+		})
+	})
+
+	context("QuoteKey", func() {
+		it("leaves simple keys bare", func() {
+			Expect(labels.QuoteKey("org.opencontainers.image.title")).To(Equal("org.opencontainers.image.title"))
+		})
+
+		it("quotes other keys", func() {
+			Expect(labels.QuoteKey("a key")).To(Equal(`"a key"`))
+			Expect(labels.QuoteKey("a=b")).To(Equal(`"a=b"`))
+			Expect(labels.QuoteKey("$HOME")).To(Equal(`"$HOME"`))
+			Expect(labels.QuoteKey("{a}")).To(Equal(`"{a}"`))
+			Expect(labels.QuoteKey(`a\`)).To(Equal(`"""a\\"""`))
+		})
+	})
+
+	context("FormatLabels", func() {
+		it("formats labels sorted by key", func() {
+			Expect(labels.FormatLabels(map[string]string{
+				"foxtrot": "golf hotel",
+				"alpha":   "bravo",
+				"charlie": "",
+			})).To(Equal(`alpha=bravo charlie="" foxtrot="golf hotel"`))
+		})
+
+		it("formats no labels", func() {
+			Expect(labels.FormatLabels(nil)).To(BeEmpty())
+		})
+
+		it("parses back to the same labels", func() {
+			t.Setenv("HOME", "/home/cnb")
+
+			f := func(m map[string]string) bool {
+				delete(m, "")
+
+				p, err := labels.ParseLabels(labels.FormatLabels(m))
+				return err == nil && reflect.DeepEqual(p, m)
+			}
+
+			Expect(quick.Check(f, &quick.Config{MaxCount: 2000})).To(Succeed())
+
+			for _, m := range []map[string]string{
+				{"a": `\`, "b": `\\`, "c": `\"`, "d": `'`, "e": `"""`, "f": `'''`},
+				{"a": "${HOME}", "b": `\${HOME}`, "c": "$", "d": `\u0041`, "e": "\r\n", "f": "\x00"},
+				{" a": "x", "a ": "y", `"`: "z", "'": "w", "=": "v", `\`: "u", "=\"'": "t"},
+			} {
+				Expect(f(m)).To(BeTrue(), labels.FormatLabels(m))
+			}
+		})
+	})
+}
+
+func FuzzFormatLabels(f *testing.F) {
+	f.Add("alpha", "bravo", "charlie", "delta echo")
+	f.Add("a=b", `it's "quoted"`, `"""`, "${HOME}\n\t\\")
+	f.Add("\x00", "\xff", "'", `\u0041`)
+
+	f.Fuzz(func(t *testing.T, k1 string, v1 string, k2 string, v2 string) {
+		m := map[string]string{k1: v1, k2: v2}
+		delete(m, "")
+
+		s := labels.FormatLabels(m)
+
+		p, err := labels.ParseLabels(s)
+		if err != nil {
+			t.Fatalf("unable to parse %q\n%s", s, err)
+		}
+
+		if !reflect.DeepEqual(p, m) {
+			t.Fatalf("%q parsed to %q, expected %q", s, p, m)
+		}
+	})
+}
@@ -0,0 +1,394 @@
+/*
+ * Copyright 2018-2025 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package labels
+
+import (
+	"bufio"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/buildpacks/libcnb/v2"
+	"github.com/paketo-buildpacks/libpak/v2/log"
+)
+
+// gitLabels returns the OCI revision, source and ref.name labels of an application's git repository, omitting any
+// that cannot be determined.
+func gitLabels(applicationPath string, logger log.Logger) ([]libcnb.Label, error) {
+	g, ok, err := NewGitRepository(applicationPath)
+	if err != nil {
+		return nil, err
+	} else if !ok {
+		logger.Body("No git repository found in application")
+		return nil, nil
+	}
+
+	m, err := g.Metadata()
+	if err != nil {
+		return nil, err
+	}
+
+	var labels []libcnb.Label
+	for _, l := range []libcnb.Label{
+		{Key: Labels["BP_OCI_REVISION"], Value: m.Revision},
+		{Key: Labels["BP_OCI_SOURCE"], Value: m.Source},
+		{Key: Labels["BP_OCI_REF_NAME"], Value: m.RefName},
+	} {
+		if l.Value != "" {
+			logger.Bodyf("Using %s=%s from git", l.Key, l.Value)
+			labels = append(labels, l)
+		}
+	}
+
+	return labels, nil
+}
+
+// GitRepository is a git repository read directly from its files, without a git binary.
+type GitRepository struct {
+	// Dir is the git directory holding HEAD, e.g. <application>/.git or <repository>/.git/worktrees/<name>.
+	Dir string
+
+	// CommonDir is the git directory holding refs, packed-refs and config. It differs from Dir for worktrees.
+	CommonDir string
+}
+
+// GitMetadata is the metadata of a git repository used to populate image labels.
+type GitMetadata struct {
+	// Revision is the commit SHA of HEAD.
+	Revision string
+
+	// RefName is the current branch, or a tag pointing at HEAD if it is detached.
+	RefName string
+
+	// Source is the URL of the origin remote, with any credentials removed.
+	Source string
+}
+
+// NewGitRepository returns the git repository of an application.
+//
+// The application may contain either a .git directory or a .git file containing a "gitdir: <path>" line, as
+// created for worktrees and submodules. The boolean is false if the application is not a git repository.
+func NewGitRepository(applicationPath string) (GitRepository, bool, error) {
+	dotGit := filepath.Join(applicationPath, ".git")
+
+	fi, err := os.Stat(dotGit)
+	if os.IsNotExist(err) {
+		return GitRepository{}, false, nil
+	} else if err != nil {
+		return GitRepository{}, false, fmt.Errorf("unable to stat %s\n%w", dotGit, err)
+	}
+
+	dir := dotGit
+	if !fi.IsDir() {
+		b, err := os.ReadFile(dotGit)
+		if err != nil {
+			return GitRepository{}, false, fmt.Errorf("unable to read %s\n%w", dotGit, err)
+		}
+
+		s := strings.TrimSpace(string(b))
+		if !strings.HasPrefix(s, "gitdir:") {
+			return GitRepository{}, false, fmt.Errorf("unable to find gitdir in %s", dotGit)
+		}
+
+		dir = strings.TrimSpace(strings.TrimPrefix(s, "gitdir:"))
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(applicationPath, dir)
+		}
+	}
+
+	common := dir
+	if b, err := os.ReadFile(filepath.Join(dir, "commondir")); err == nil {
+		common = strings.TrimSpace(string(b))
+		if !filepath.IsAbs(common) {
+			common = filepath.Join(dir, common)
+		}
+	} else if !os.IsNotExist(err) {
+		return GitRepository{}, false, fmt.Errorf("unable to read %s\n%w", filepath.Join(dir, "commondir"), err)
+	}
+
+	return GitRepository{Dir: filepath.Clean(dir), CommonDir: filepath.Clean(common)}, true, nil
+}
+
+// Metadata reads the revision, ref name and origin URL of the repository.
+func (g GitRepository) Metadata() (GitMetadata, error) {
+	var m GitMetadata
+
+	head, err := os.ReadFile(filepath.Join(g.Dir, "HEAD"))
+	if err != nil {
+		return GitMetadata{}, fmt.Errorf("unable to read HEAD\n%w", err)
+	}
+
+	s := strings.TrimSpace(string(head))
+	if strings.HasPrefix(s, "ref:") {
+		ref := strings.TrimSpace(strings.TrimPrefix(s, "ref:"))
+
+		if m.Revision, err = g.ResolveRef(ref); err != nil {
+			return GitMetadata{}, fmt.Errorf("unable to resolve %s\n%w", ref, err)
+		}
+		m.RefName = strings.TrimPrefix(strings.TrimPrefix(ref, "refs/heads/"), "refs/tags/")
+	} else {
+		m.Revision = s
+		if m.RefName, err = g.tagFor(m.Revision); err != nil {
+			return GitMetadata{}, fmt.Errorf("unable to find tag for %s\n%w", m.Revision, err)
+		}
+	}
+
+	if m.Source, err = g.RemoteURL("origin"); err != nil {
+		return GitMetadata{}, fmt.Errorf("unable to read origin remote\n%w", err)
+	}
+
+	return m, nil
+}
+
+// ResolveRef returns the commit SHA of a fully qualified ref such as refs/heads/main, following symbolic refs. An
+// unborn branch resolves to an empty string.
+func (g GitRepository) ResolveRef(ref string) (string, error) {
+	for range 10 {
+		b, err := g.readRef(ref)
+		if err != nil {
+			return "", err
+		}
+
+		s := strings.TrimSpace(b)
+		if !strings.HasPrefix(s, "ref:") {
+			return s, nil
+		}
+		ref = strings.TrimSpace(strings.TrimPrefix(s, "ref:"))
+	}
+
+	return "", fmt.Errorf("too many levels of symbolic refs")
+}
+
+func (g GitRepository) readRef(ref string) (string, error) {
+	for _, dir := range []string{g.Dir, g.CommonDir} {
+		if b, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(ref))); err == nil {
+			return string(b), nil
+		} else if !os.IsNotExist(err) {
+			return "", fmt.Errorf("unable to read %s\n%w", ref, err)
+		}
+	}
+
+	refs, err := g.packedRefs()
+	if err != nil {
+		return "", err
+	}
+
+	return refs[ref], nil
+}
+
+// packedRefs returns the refs in packed-refs, with annotated tags also included under "<ref>^{}" pointing at the
+// commit they peel to.
+func (g GitRepository) packedRefs() (map[string]string, error) {
+	refs := make(map[string]string)
+
+	file := filepath.Join(g.CommonDir, "packed-refs")
+	f, err := os.Open(file)
+	if os.IsNotExist(err) {
+		return refs, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("unable to open %s\n%w", file, err)
+	}
+	defer f.Close()
+
+	var last string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		switch {
+		case line == "" || strings.HasPrefix(line, "#"):
+			continue
+		case strings.HasPrefix(line, "^"):
+			if last != "" {
+				refs[last+"^{}"] = strings.TrimPrefix(line, "^")
+			}
+		default:
+			if sha, ref, ok := strings.Cut(line, " "); ok {
+				refs[ref] = sha
+				last = ref
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("unable to read %s\n%w", file, err)
+	}
+
+	return refs, nil
+}
+
+// tagFor returns the alphabetically first tag pointing at a commit, or an empty string if there is none.
+func (g GitRepository) tagFor(sha string) (string, error) {
+	var tags []string
+
+	root := filepath.Join(g.CommonDir, "refs", "tags")
+	err := filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+		if os.IsNotExist(err) {
+			return filepath.SkipDir
+		} else if err != nil {
+			return err
+		}
+
+		if d.IsDir() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(filepath.Join(g.CommonDir, "refs"), path)
+		if err != nil {
+			return err
+		}
+
+		ref := "refs/" + filepath.ToSlash(rel)
+		s, err := g.ResolveRef(ref)
+		if err != nil {
+			return err
+		}
+
+		// An annotated tag points at a tag object rather than the commit. A tag whose object cannot be read, such as
+		// one missing from a shallow clone, is not peeled.
+		if s != sha && s != "" {
+			if p, err := g.peelTag(s); err == nil {
+				s = p
+			}
+		}
+
+		if s == sha {
+			tags = append(tags, strings.TrimPrefix(ref, "refs/tags/"))
+		}
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("unable to walk %s\n%w", root, err)
+	}
+
+	refs, err := g.packedRefs()
+	if err != nil {
+		return "", err
+	}
+
+	for ref, s := range refs {
+		if s != sha || !strings.HasPrefix(ref, "refs/tags/") {
+			continue
+		}
+		tags = append(tags, strings.TrimSuffix(strings.TrimPrefix(ref, "refs/tags/"), "^{}"))
+	}
+
+	if len(tags) == 0 {
+		return "", nil
+	}
+
+	sort.Strings(tags)
+	return tags[0], nil
+}
+
+// peelTag returns the object that a tag object points at, following tags of tags, or the name itself if it is not a
+// tag object.
+func (g GitRepository) peelTag(sha string) (string, error) {
+	for range 10 {
+		kind, data, err := g.ReadObject(sha)
+		if err != nil {
+			return "", err
+		} else if kind != "tag" {
+			return sha, nil
+		}
+
+		object, _, _ := strings.Cut(string(data), "\n")
+		if !strings.HasPrefix(object, "object ") {
+			return "", fmt.Errorf("unable to find object of tag %s", sha)
+		}
+		sha = strings.TrimSpace(strings.TrimPrefix(object, "object "))
+	}
+
+	return "", fmt.Errorf("unable to peel tag %s, too many levels of tags", sha)
+}
+
+// RemoteURL returns the URL of a remote from the repository config, with any user information removed. It returns an
+// empty string if the remote is not configured.
+func (g GitRepository) RemoteURL(remote string) (string, error) {
+	file := filepath.Join(g.CommonDir, "config")
+	f, err := os.Open(file)
+	if os.IsNotExist(err) {
+		return "", nil
+	} else if err != nil {
+		return "", fmt.Errorf("unable to open %s\n%w", file, err)
+	}
+	defer f.Close()
+
+	section := fmt.Sprintf(`remote "%s"`, remote)
+
+	var (
+		current string
+		raw     string
+	)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			current = strings.TrimSpace(strings.TrimSuffix(strings.TrimPrefix(line, "["), "]"))
+			continue
+		}
+
+		if current != section {
+			continue
+		}
+
+		if k, v, ok := strings.Cut(line, "="); ok && strings.EqualFold(strings.TrimSpace(k), "url") {
+			raw = strings.Trim(strings.TrimSpace(v), `"`)
+			break
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", fmt.Errorf("unable to read %s\n%w", file, err)
+	}
+
+	return redactURL(raw), nil
+}
+
+// redactURL removes user information, which may hold a token or password, from URLs. scp-like SSH addresses such as
+// git@github.com:org/repo.git are returned unchanged. If a URL cannot be parsed, everything between its scheme and
+// the last '@' is removed, so that a malformed password is not kept.
+func redactURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		scheme, rest, ok := strings.Cut(raw, "://")
+		if i := strings.LastIndexByte(rest, '@'); ok && i >= 0 {
+			return scheme + "://" + rest[i+1:]
+		}
+		return raw
+	}
+	if u.Scheme == "" || u.User == nil {
+		return raw
+	}
+
+	if u.Scheme == "ssh" || u.Scheme == "git+ssh" {
+		if _, ok := u.User.Password(); !ok {
+			return raw
+		}
+		u.User = url.User(u.User.Username())
+		return u.String()
+	}
+
+	u.User = nil
+	return u.String()
+}
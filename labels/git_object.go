@@ -0,0 +1,407 @@
+/*
+ * Copyright 2018-2025 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package labels
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Git object types as stored in pack files.
+const (
+	gitObjectCommit   = 1
+	gitObjectTree     = 2
+	gitObjectBlob     = 3
+	gitObjectTag      = 4
+	gitObjectOfsDelta = 6
+	gitObjectRefDelta = 7
+)
+
+var gitObjectTypes = map[int]string{
+	gitObjectCommit: "commit",
+	gitObjectTree:   "tree",
+	gitObjectBlob:   "blob",
+	gitObjectTag:    "tag",
+}
+
+// CommitTime returns the committer time of a commit.
+func (g GitRepository) CommitTime(sha string) (time.Time, error) {
+	kind, data, err := g.ReadObject(sha)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	if kind != "commit" {
+		return time.Time{}, fmt.Errorf("object %s is a %s, not a commit", sha, kind)
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			break
+		}
+
+		if !strings.HasPrefix(line, "committer ") {
+			continue
+		}
+
+		// committer Name <email> 1700000000 +0100
+		fields := strings.Fields(line[strings.LastIndex(line, ">")+1:])
+		if len(fields) != 2 {
+			return time.Time{}, fmt.Errorf("unable to parse %q", line)
+		}
+
+		seconds, err := strconv.ParseInt(fields[0], 10, 64)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("unable to parse commit time %q\n%w", fields[0], err)
+		}
+
+		return time.Unix(seconds, 0).UTC(), nil
+	}
+
+	return time.Time{}, fmt.Errorf("unable to find committer in commit %s", sha)
+}
+
+// GitCommit is the part of a commit used to populate image labels.
+type GitCommit struct {
+	// Author is the author as "Name <email>".
+	Author string
+
+	// Parents are the names of the parent commits, first parent first.
+	Parents []string
+}
+
+// ReadCommit returns the author and parents of a commit.
+func (g GitRepository) ReadCommit(sha string) (GitCommit, error) {
+	kind, data, err := g.ReadObject(sha)
+	if err != nil {
+		return GitCommit{}, err
+	}
+
+	if kind != "commit" {
+		return GitCommit{}, fmt.Errorf("object %s is a %s, not a commit", sha, kind)
+	}
+
+	var c GitCommit
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			break
+		}
+
+		switch {
+		case strings.HasPrefix(line, "parent "):
+			c.Parents = append(c.Parents, strings.TrimSpace(strings.TrimPrefix(line, "parent ")))
+		case strings.HasPrefix(line, "author "):
+			// author Name <email> 1700000000 +0100
+			if i := strings.LastIndex(line, ">"); i > 0 {
+				c.Author = strings.TrimSpace(line[len("author ") : i+1])
+			}
+		}
+	}
+
+	return c, nil
+}
+
+// ReadObject returns the type and content of an object, read from either a loose object file or a pack file.
+func (g GitRepository) ReadObject(sha string) (string, []byte, error) {
+	if _, err := hex.DecodeString(sha); err != nil || len(sha) < 4 {
+		return "", nil, fmt.Errorf("invalid object name %q", sha)
+	}
+
+	file := filepath.Join(g.CommonDir, "objects", sha[:2], sha[2:])
+	if f, err := os.Open(file); err == nil {
+		defer f.Close()
+		return readLooseObject(f)
+	} else if !os.IsNotExist(err) {
+		return "", nil, fmt.Errorf("unable to open %s\n%w", file, err)
+	}
+
+	indices, err := filepath.Glob(filepath.Join(g.CommonDir, "objects", "pack", "*.idx"))
+	if err != nil {
+		return "", nil, fmt.Errorf("unable to list pack indices\n%w", err)
+	}
+
+	for _, idx := range indices {
+		offset, ok, err := findPackOffset(idx, sha)
+		if err != nil {
+			return "", nil, err
+		} else if !ok {
+			continue
+		}
+
+		pack := strings.TrimSuffix(idx, ".idx") + ".pack"
+		f, err := os.Open(pack)
+		if err != nil {
+			return "", nil, fmt.Errorf("unable to open %s\n%w", pack, err)
+		}
+		defer f.Close()
+
+		kind, data, err := g.readPackObject(f, offset, len(sha)/2, 0)
+		if err != nil {
+			return "", nil, fmt.Errorf("unable to read %s from %s\n%w", sha, pack, err)
+		}
+		return gitObjectTypes[kind], data, nil
+	}
+
+	return "", nil, fmt.Errorf("unable to find object %s", sha)
+}
+
+func readLooseObject(r io.Reader) (string, []byte, error) {
+	z, err := zlib.NewReader(r)
+	if err != nil {
+		return "", nil, fmt.Errorf("unable to decompress object\n%w", err)
+	}
+	defer z.Close()
+
+	b, err := io.ReadAll(z)
+	if err != nil {
+		return "", nil, fmt.Errorf("unable to decompress object\n%w", err)
+	}
+
+	header, data, ok := bytes.Cut(b, []byte{0})
+	if !ok {
+		return "", nil, fmt.Errorf("unable to find object header")
+	}
+
+	kind, _, _ := strings.Cut(string(header), " ")
+	return kind, data, nil
+}
+
+// findPackOffset looks up an object in a version 2 pack index, returning its offset in the pack file.
+func findPackOffset(idx string, sha string) (int64, bool, error) {
+	b, err := os.ReadFile(idx)
+	if err != nil {
+		return 0, false, fmt.Errorf("unable to read %s\n%w", idx, err)
+	}
+
+	name, _ := hex.DecodeString(sha)
+	size := len(name)
+
+	if len(b) < 8+256*4 || !bytes.Equal(b[:4], []byte{0xff, 't', 'O', 'c'}) || binary.BigEndian.Uint32(b[4:8]) != 2 {
+		return 0, false, fmt.Errorf("unsupported pack index %s", idx)
+	}
+
+	fanout := b[8 : 8+256*4]
+	count := int(binary.BigEndian.Uint32(fanout[255*4:]))
+
+	lo := 0
+	if name[0] > 0 {
+		lo = int(binary.BigEndian.Uint32(fanout[(int(name[0])-1)*4:]))
+	}
+	hi := int(binary.BigEndian.Uint32(fanout[int(name[0])*4:]))
+
+	names := 8 + 256*4
+	offsets := names + count*size + count*4
+	large := offsets + count*4
+	if len(b) < large {
+		return 0, false, fmt.Errorf("truncated pack index %s", idx)
+	}
+
+	for lo < hi {
+		mid := (lo + hi) / 2
+		switch c := bytes.Compare(b[names+mid*size:names+(mid+1)*size], name); {
+		case c < 0:
+			lo = mid + 1
+		case c > 0:
+			hi = mid
+		default:
+			offset := binary.BigEndian.Uint32(b[offsets+mid*4:])
+			if offset&0x80000000 == 0 {
+				return int64(offset), true, nil
+			}
+
+			i := large + int(offset&0x7fffffff)*8
+			if len(b) < i+8 {
+				return 0, false, fmt.Errorf("truncated pack index %s", idx)
+			}
+			return int64(binary.BigEndian.Uint64(b[i:])), true, nil
+		}
+	}
+
+	return 0, false, nil
+}
+
+func (g GitRepository) readPackObject(f *os.File, offset int64, hashSize int, depth int) (int, []byte, error) {
+	if depth > 50 {
+		return 0, nil, fmt.Errorf("delta chain too deep")
+	}
+
+	r := bufio.NewReader(io.NewSectionReader(f, offset, 1<<62))
+
+	c, err := r.ReadByte()
+	if err != nil {
+		return 0, nil, err
+	}
+	kind := int(c>>4) & 7
+	for c&0x80 != 0 {
+		if c, err = r.ReadByte(); err != nil {
+			return 0, nil, err
+		}
+	}
+
+	var (
+		baseKind int
+		base     []byte
+	)
+
+	switch kind {
+	case gitObjectCommit, gitObjectTree, gitObjectBlob, gitObjectTag:
+		data, err := inflate(r)
+		return kind, data, err
+
+	case gitObjectOfsDelta:
+		if c, err = r.ReadByte(); err != nil {
+			return 0, nil, err
+		}
+		distance := int64(c & 0x7f)
+		for c&0x80 != 0 {
+			if c, err = r.ReadByte(); err != nil {
+				return 0, nil, err
+			}
+			distance = ((distance + 1) << 7) | int64(c&0x7f)
+		}
+
+		if baseKind, base, err = g.readPackObject(f, offset-distance, hashSize, depth+1); err != nil {
+			return 0, nil, err
+		}
+
+	case gitObjectRefDelta:
+		name := make([]byte, hashSize)
+		if _, err := io.ReadFull(r, name); err != nil {
+			return 0, nil, err
+		}
+
+		var kind string
+		if kind, base, err = g.ReadObject(hex.EncodeToString(name)); err != nil {
+			return 0, nil, err
+		}
+		for k, v := range gitObjectTypes {
+			if v == kind {
+				baseKind = k
+			}
+		}
+
+	default:
+		return 0, nil, fmt.Errorf("unsupported pack object type %d", kind)
+	}
+
+	delta, err := inflate(r)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	data, err := applyDelta(base, delta)
+	return baseKind, data, err
+}
+
+func inflate(r io.Reader) ([]byte, error) {
+	z, err := zlib.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decompress object\n%w", err)
+	}
+	defer z.Close()
+
+	return io.ReadAll(z)
+}
+
+func applyDelta(base []byte, delta []byte) ([]byte, error) {
+	varint := func() (int, error) {
+		n, shift := 0, 0
+		for {
+			if len(delta) == 0 {
+				return 0, fmt.Errorf("truncated delta")
+			}
+			c := delta[0]
+			delta = delta[1:]
+			n |= int(c&0x7f) << shift
+			shift += 7
+			if c&0x80 == 0 {
+				return n, nil
+			}
+		}
+	}
+
+	size, err := varint()
+	if err != nil {
+		return nil, err
+	}
+	if size != len(base) {
+		return nil, fmt.Errorf("delta base size %d does not match %d", size, len(base))
+	}
+
+	if size, err = varint(); err != nil {
+		return nil, err
+	}
+
+	out := make([]byte, 0, size)
+	for len(delta) > 0 {
+		c := delta[0]
+		delta = delta[1:]
+
+		if c&0x80 == 0 {
+			if c == 0 || int(c) > len(delta) {
+				return nil, fmt.Errorf("invalid delta insert")
+			}
+			out = append(out, delta[:c]...)
+			delta = delta[c:]
+			continue
+		}
+
+		var offset, length int
+		for i := 0; i < 7; i++ {
+			if c&(1<<i) == 0 {
+				continue
+			}
+			if len(delta) == 0 {
+				return nil, fmt.Errorf("truncated delta")
+			}
+			if i < 4 {
+				offset |= int(delta[0]) << (8 * i)
+			} else {
+				length |= int(delta[0]) << (8 * (i - 4))
+			}
+			delta = delta[1:]
+		}
+		if length == 0 {
+			length = 0x10000
+		}
+
+		if offset+length > len(base) {
+			return nil, fmt.Errorf("invalid delta copy")
+		}
+		out = append(out, base[offset:offset+length]...)
+	}
+
+	if len(out) != size {
+		return nil, fmt.Errorf("delta result size %d does not match %d", len(out), size)
+	}
+
+	return out, nil
+}
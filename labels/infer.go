@@ -0,0 +1,493 @@
+/*
+ * Copyright 2018-2025 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package labels
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/paketo-buildpacks/libpak/v2/log"
+)
+
+// InferredLabel is an image label inferred from a file in the application.
+type InferredLabel struct {
+	// Key is the label key.
+	Key string
+
+	// Value is the label value.
+	Value string
+
+	// Source is the path, relative to the application, of the file the label was inferred from.
+	Source string
+}
+
+// Manifest is a language package manifest from which OCI image labels can be inferred.
+type Manifest struct {
+	// Name is the file name of the manifest in the application directory.
+	Name string
+
+	// Read reads the manifest returning its fields keyed by the BP_OCI_* name of the label they map to, logging any
+	// field that it ignores.
+	Read func(b []byte, logger log.Logger) (map[string]string, error)
+}
+
+// Manifests are the supported manifests, in order of precedence.
+var Manifests = []Manifest{
+	{Name: "package.json", Read: readPackageJSON},
+	{Name: "pom.xml", Read: readPomXML},
+	{Name: "pyproject.toml", Read: readPyprojectTOML},
+	{Name: "Cargo.toml", Read: readCargoTOML},
+	{Name: "composer.json", Read: readComposerJSON},
+	{Name: "go.mod", Read: readGoMod},
+}
+
+// InferLabels returns the OCI image labels that can be inferred from the manifests in an application. When more than
+// one manifest provides a label, the first of Manifests wins.
+func InferLabels(applicationPath string, logger log.Logger) ([]InferredLabel, error) {
+	var inferred []InferredLabel
+	seen := make(map[string]bool)
+
+	for _, m := range Manifests {
+		file := filepath.Join(applicationPath, m.Name)
+		b, err := os.ReadFile(file)
+		if os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return nil, fmt.Errorf("unable to read %s\n%w", file, err)
+		}
+
+		fields, err := m.Read(b, logger)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse %s\n%w", file, err)
+		}
+
+		for _, k := range sortedKeys(Labels) {
+			v := strings.TrimSpace(fields[k])
+			if v == "" || seen[Labels[k]] {
+				continue
+			}
+
+			inferred = append(inferred, InferredLabel{Key: Labels[k], Value: v, Source: m.Name})
+			seen[Labels[k]] = true
+		}
+	}
+
+	return inferred, nil
+}
+
+// person formats a name and optional email as "Name <email>".
+func person(name string, email string) string {
+	name, email = strings.TrimSpace(name), strings.TrimSpace(email)
+	switch {
+	case name == "":
+		return email
+	case email == "":
+		return name
+	default:
+		return fmt.Sprintf("%s <%s>", name, email)
+	}
+}
+
+func joinNonEmpty(s []string, sep string) string {
+	var out []string
+	for _, v := range s {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return strings.Join(out, sep)
+}
+
+// stringOr returns a JSON value that is either a string or an object as a string, using the named field of an
+// object.
+func stringOr(raw json.RawMessage, field string) string {
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return s
+	}
+
+	var m map[string]interface{}
+	if err := json.Unmarshal(raw, &m); err == nil {
+		if s, ok := m[field].(string); ok {
+			return s
+		}
+	}
+
+	return ""
+}
+
+// repositoryURL converts npm-style repository shorthand and git+ URLs to a browsable URL.
+func repositoryURL(s string) string {
+	s = strings.TrimSpace(s)
+	s = strings.TrimPrefix(s, "git+")
+
+	for prefix, host := range map[string]string{
+		"github:":    "https://github.com/",
+		"gitlab:":    "https://gitlab.com/",
+		"bitbucket:": "https://bitbucket.org/",
+	} {
+		if strings.HasPrefix(s, prefix) {
+			return host + strings.TrimPrefix(s, prefix)
+		}
+	}
+
+	return s
+}
+
+func readPackageJSON(b []byte, _ log.Logger) (map[string]string, error) {
+	var p struct {
+		Name        string          `json:"name"`
+		Version     string          `json:"version"`
+		Description string          `json:"description"`
+		License     json.RawMessage `json:"license"`
+		Author      json.RawMessage `json:"author"`
+		Homepage    string          `json:"homepage"`
+		Repository  json.RawMessage `json:"repository"`
+	}
+	if err := json.Unmarshal(b, &p); err != nil {
+		return nil, err
+	}
+
+	author := stringOr(p.Author, "name")
+	var a struct {
+		Name  string `json:"name"`
+		Email string `json:"email"`
+	}
+	if err := json.Unmarshal(p.Author, &a); err == nil && a.Name != "" {
+		author = person(a.Name, a.Email)
+	}
+
+	return map[string]string{
+		"BP_OCI_TITLE":       p.Name,
+		"BP_OCI_VERSION":     p.Version,
+		"BP_OCI_DESCRIPTION": p.Description,
+		"BP_OCI_LICENSES":    stringOr(p.License, "type"),
+		"BP_OCI_AUTHORS":     author,
+		"BP_OCI_URL":         p.Homepage,
+		"BP_OCI_SOURCE":      repositoryURL(stringOr(p.Repository, "url")),
+	}, nil
+}
+
+func readComposerJSON(b []byte, _ log.Logger) (map[string]string, error) {
+	var c struct {
+		Name        string          `json:"name"`
+		Version     string          `json:"version"`
+		Description string          `json:"description"`
+		License     json.RawMessage `json:"license"`
+		Authors     []struct {
+			Name  string `json:"name"`
+			Email string `json:"email"`
+		} `json:"authors"`
+		Homepage string `json:"homepage"`
+		Support  struct {
+			Source string `json:"source"`
+			Docs   string `json:"docs"`
+		} `json:"support"`
+	}
+	if err := json.Unmarshal(b, &c); err != nil {
+		return nil, err
+	}
+
+	license := stringOr(c.License, "")
+	var licenses []string
+	if err := json.Unmarshal(c.License, &licenses); err == nil {
+		license = joinNonEmpty(licenses, " OR ")
+		if len(licenses) > 1 {
+			license = "(" + license + ")"
+		}
+	}
+
+	var authors []string
+	for _, a := range c.Authors {
+		authors = append(authors, person(a.Name, a.Email))
+	}
+
+	return map[string]string{
+		"BP_OCI_TITLE":         c.Name,
+		"BP_OCI_VERSION":       c.Version,
+		"BP_OCI_DESCRIPTION":   c.Description,
+		"BP_OCI_LICENSES":      license,
+		"BP_OCI_AUTHORS":       joinNonEmpty(authors, ", "),
+		"BP_OCI_URL":           c.Homepage,
+		"BP_OCI_SOURCE":        c.Support.Source,
+		"BP_OCI_DOCUMENTATION": c.Support.Docs,
+	}, nil
+}
+
+// mavenLicenses maps common Maven license names to SPDX identifiers.
+var mavenLicenses = map[string]string{
+	"apache license, version 2.0":              "Apache-2.0",
+	"apache license 2.0":                       "Apache-2.0",
+	"apache 2.0":                               "Apache-2.0",
+	"apache-2.0":                               "Apache-2.0",
+	"the apache license, version 2.0":          "Apache-2.0",
+	"the apache software license, version 2.0": "Apache-2.0",
+	"mit license":                              "MIT",
+	"the mit license":                          "MIT",
+	"mit":                                      "MIT",
+	"bsd 3-clause license":                     "BSD-3-Clause",
+	"bsd-3-clause":                             "BSD-3-Clause",
+	"bsd 2-clause license":                     "BSD-2-Clause",
+	"bsd-2-clause":                             "BSD-2-Clause",
+	"eclipse public license 2.0":               "EPL-2.0",
+	"eclipse public license - v 2.0":           "EPL-2.0",
+	"epl-2.0":                                  "EPL-2.0",
+	"mozilla public license 2.0":               "MPL-2.0",
+	"mpl-2.0":                                  "MPL-2.0",
+}
+
+func readPomXML(b []byte, logger log.Logger) (map[string]string, error) {
+	type developer struct {
+		Name  string `xml:"name"`
+		Email string `xml:"email"`
+	}
+
+	var p struct {
+		GroupID     string `xml:"groupId"`
+		ArtifactID  string `xml:"artifactId"`
+		Version     string `xml:"version"`
+		Name        string `xml:"name"`
+		Description string `xml:"description"`
+		URL         string `xml:"url"`
+		Parent      struct {
+			Version string `xml:"version"`
+		} `xml:"parent"`
+		Organization struct {
+			Name string `xml:"name"`
+		} `xml:"organization"`
+		Licenses []struct {
+			Name string `xml:"name"`
+		} `xml:"licenses>license"`
+		Developers []developer `xml:"developers>developer"`
+		SCM        struct {
+			URL string `xml:"url"`
+		} `xml:"scm"`
+	}
+	if err := xml.Unmarshal(b, &p); err != nil {
+		return nil, err
+	}
+
+	// unresolved properties such as ${revision} are not useful as label values
+	literal := func(s string) string {
+		if strings.Contains(s, "${") {
+			return ""
+		}
+		return strings.TrimSpace(s)
+	}
+
+	title := literal(p.Name)
+	if title == "" {
+		title = literal(p.ArtifactID)
+	}
+
+	version := literal(p.Version)
+	if version == "" {
+		version = literal(p.Parent.Version)
+	}
+
+	// Maven reads several licenses as alternatives the user can choose between
+	var licenses []string
+	seen := make(map[string]bool)
+	for _, l := range p.Licenses {
+		name := strings.TrimSpace(l.Name)
+		id, ok := mavenLicenses[strings.ToLower(name)]
+		if !ok {
+			id, ok = spdxLicenses[strings.ToLower(name)]
+		}
+		if !ok {
+			if name != "" {
+				logger.Bodyf("Ignoring license %q in pom.xml without an SPDX identifier, set $BP_OCI_LICENSES instead", name)
+			}
+			continue
+		}
+		if !seen[id] {
+			licenses = append(licenses, id)
+			seen[id] = true
+		}
+	}
+	license := strings.Join(licenses, " OR ")
+	if len(licenses) > 1 {
+		license = "(" + license + ")"
+	}
+
+	var authors []string
+	for _, d := range p.Developers {
+		authors = append(authors, person(d.Name, d.Email))
+	}
+
+	return map[string]string{
+		"BP_OCI_TITLE":       title,
+		"BP_OCI_VERSION":     version,
+		"BP_OCI_DESCRIPTION": literal(p.Description),
+		"BP_OCI_LICENSES":    license,
+		"BP_OCI_AUTHORS":     joinNonEmpty(authors, ", "),
+		"BP_OCI_URL":         literal(p.URL),
+		"BP_OCI_SOURCE":      literal(p.SCM.URL),
+		"BP_OCI_VENDOR":      literal(p.Organization.Name),
+	}, nil
+}
+
+// tomlPerson formats an entry of a TOML authors list, which is either a "Name <email>" string or a table with name
+// and email keys.
+func tomlPerson(v interface{}) string {
+	switch a := v.(type) {
+	case string:
+		return a
+	case map[string]interface{}:
+		name, _ := a["name"].(string)
+		email, _ := a["email"].(string)
+		return person(name, email)
+	}
+	return ""
+}
+
+func tomlString(v interface{}) string {
+	s, _ := v.(string)
+	return s
+}
+
+func tomlAuthors(v interface{}) string {
+	list, _ := v.([]interface{})
+
+	var authors []string
+	for _, a := range list {
+		authors = append(authors, tomlPerson(a))
+	}
+	return joinNonEmpty(authors, ", ")
+}
+
+// tomlURL returns the first of a set of keys, compared case-insensitively, in a TOML table of URLs.
+func tomlURL(v interface{}, keys ...string) string {
+	m, _ := v.(map[string]interface{})
+	for _, k := range keys {
+		for name, url := range m {
+			if strings.EqualFold(name, k) {
+				return tomlString(url)
+			}
+		}
+	}
+	return ""
+}
+
+func readPyprojectTOML(b []byte, _ log.Logger) (map[string]string, error) {
+	var p struct {
+		Project map[string]interface{} `toml:"project"`
+		Tool    struct {
+			Poetry map[string]interface{} `toml:"poetry"`
+		} `toml:"tool"`
+	}
+	if err := toml.Unmarshal(b, &p); err != nil {
+		return nil, err
+	}
+
+	if p.Project != nil {
+		license := tomlString(p.Project["license"])
+		if m, ok := p.Project["license"].(map[string]interface{}); ok {
+			license = tomlString(m["text"])
+		}
+
+		return map[string]string{
+			"BP_OCI_TITLE":         tomlString(p.Project["name"]),
+			"BP_OCI_VERSION":       tomlString(p.Project["version"]),
+			"BP_OCI_DESCRIPTION":   tomlString(p.Project["description"]),
+			"BP_OCI_LICENSES":      license,
+			"BP_OCI_AUTHORS":       tomlAuthors(p.Project["authors"]),
+			"BP_OCI_URL":           tomlURL(p.Project["urls"], "Homepage"),
+			"BP_OCI_SOURCE":        tomlURL(p.Project["urls"], "Source", "Repository"),
+			"BP_OCI_DOCUMENTATION": tomlURL(p.Project["urls"], "Documentation"),
+		}, nil
+	}
+
+	return map[string]string{
+		"BP_OCI_TITLE":         tomlString(p.Tool.Poetry["name"]),
+		"BP_OCI_VERSION":       tomlString(p.Tool.Poetry["version"]),
+		"BP_OCI_DESCRIPTION":   tomlString(p.Tool.Poetry["description"]),
+		"BP_OCI_LICENSES":      tomlString(p.Tool.Poetry["license"]),
+		"BP_OCI_AUTHORS":       tomlAuthors(p.Tool.Poetry["authors"]),
+		"BP_OCI_URL":           tomlString(p.Tool.Poetry["homepage"]),
+		"BP_OCI_SOURCE":        tomlString(p.Tool.Poetry["repository"]),
+		"BP_OCI_DOCUMENTATION": tomlString(p.Tool.Poetry["documentation"]),
+	}, nil
+}
+
+func readCargoTOML(b []byte, _ log.Logger) (map[string]string, error) {
+	var c struct {
+		Package map[string]interface{} `toml:"package"`
+	}
+	if err := toml.Unmarshal(b, &c); err != nil {
+		return nil, err
+	}
+
+	// fields inherited with { workspace = true } are tables and are ignored by tomlString
+	return map[string]string{
+		"BP_OCI_TITLE":         tomlString(c.Package["name"]),
+		"BP_OCI_VERSION":       tomlString(c.Package["version"]),
+		"BP_OCI_DESCRIPTION":   tomlString(c.Package["description"]),
+		"BP_OCI_LICENSES":      tomlString(c.Package["license"]),
+		"BP_OCI_AUTHORS":       tomlAuthors(c.Package["authors"]),
+		"BP_OCI_URL":           tomlString(c.Package["homepage"]),
+		"BP_OCI_SOURCE":        tomlString(c.Package["repository"]),
+		"BP_OCI_DOCUMENTATION": tomlString(c.Package["documentation"]),
+	}, nil
+}
+
+// goModHosts are the code hosts whose module paths are also the URL of the source repository.
+var goModHosts = []string{"github.com/", "gitlab.com/", "bitbucket.org/", "codeberg.org/"}
+
+func readGoMod(b []byte, _ log.Logger) (map[string]string, error) {
+	var module string
+
+	scanner := bufio.NewScanner(bytes.NewReader(b))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if rest, ok := strings.CutPrefix(line, "module"); ok && (rest == "" || rest[0] == ' ' || rest[0] == '\t') {
+			module = strings.Trim(strings.TrimSpace(strings.SplitN(rest, "//", 2)[0]), `"`)
+			break
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if module == "" {
+		return nil, fmt.Errorf("unable to find module directive")
+	}
+
+	// drop a major version suffix such as /v4
+	path := module
+	if i := strings.LastIndex(path, "/"); i > 0 {
+		if s := path[i+1:]; len(s) > 1 && s[0] == 'v' && strings.Trim(s[1:], "0123456789") == "" {
+			path = path[:i]
+		}
+	}
+
+	m := map[string]string{"BP_OCI_TITLE": path[strings.LastIndex(path, "/")+1:]}
+	for _, host := range goModHosts {
+		if parts := strings.Split(path, "/"); strings.HasPrefix(path, host) && len(parts) >= 3 {
+			m["BP_OCI_SOURCE"] = "https://" + strings.Join(parts[:3], "/")
+		}
+	}
+
+	return m, nil
+}
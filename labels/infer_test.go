@@ -0,0 +1,264 @@
+/*
+ * Copyright 2018-2025 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package labels_test
+
+import (
+	"bytes"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/paketo-buildpacks/libpak/v2/log"
+	"github.com/sclevine/spec"
+
+	"github.com/paketo-buildpacks/image-labels/v4/labels"
+)
+
+func testInfer(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		buf *bytes.Buffer
+		dir string
+	)
+
+	it.Before(func() {
+		buf = &bytes.Buffer{}
+		dir = t.TempDir()
+	})
+
+	infer := func() map[string]string {
+		inferred, err := labels.InferLabels(dir, log.NewPaketoLogger(buf))
+		Expect(err).NotTo(HaveOccurred())
+
+		m := make(map[string]string)
+		for _, l := range inferred {
+			m[l.Key] = l.Value
+		}
+		return m
+	}
+
+	it("infers nothing without manifests", func() {
+		Expect(infer()).To(BeEmpty())
+	})
+
+	it("reads package.json", func() {
+		writeFiles(t, dir, map[string]string{"package.json": `{
+  "name": "my-app",
+  "version": "1.2.3",
+  "description": "An application",
+  "license": "MIT",
+  "author": {"name": "Jane Doe", "email": "jane@example.com"},
+  "homepage": "https://example.com",
+  "repository": "github:example/my-app"
+}`})
+
+		Expect(infer()).To(Equal(map[string]string{
+			"org.opencontainers.image.title":       "my-app",
+			"org.opencontainers.image.version":     "1.2.3",
+			"org.opencontainers.image.description": "An application",
+			"org.opencontainers.image.licenses":    "MIT",
+			"org.opencontainers.image.authors":     "Jane Doe <jane@example.com>",
+			"org.opencontainers.image.url":         "https://example.com",
+			"org.opencontainers.image.source":      "https://github.com/example/my-app",
+		}))
+	})
+
+	it("reads pom.xml", func() {
+		writeFiles(t, dir, map[string]string{"pom.xml": `<?xml version="1.0" encoding="UTF-8"?>
+<project xmlns="http://maven.apache.org/POM/4.0.0">
+  <parent>
+    <groupId>org.example</groupId>
+    <artifactId>parent</artifactId>
+    <version>2.0.0</version>
+  </parent>
+  <artifactId>my-app</artifactId>
+  <version>${revision}</version>
+  <description>An application</description>
+  <url>https://example.com</url>
+  <organization><name>Example, Inc.</name></organization>
+  <licenses>
+    <license><name>Apache License, Version 2.0</name></license>
+  </licenses>
+  <developers>
+    <developer><name>Jane Doe</name><email>jane@example.com</email></developer>
+    <developer><name>John Doe</name></developer>
+  </developers>
+  <scm><url>https://github.com/example/my-app</url></scm>
+</project>`})
+
+		Expect(infer()).To(Equal(map[string]string{
+			"org.opencontainers.image.title":       "my-app",
+			"org.opencontainers.image.version":     "2.0.0",
+			"org.opencontainers.image.description": "An application",
+			"org.opencontainers.image.licenses":    "Apache-2.0",
+			"org.opencontainers.image.authors":     "Jane Doe <jane@example.com>, John Doe",
+			"org.opencontainers.image.url":         "https://example.com",
+			"org.opencontainers.image.source":      "https://github.com/example/my-app",
+			"org.opencontainers.image.vendor":      "Example, Inc.",
+		}))
+	})
+
+	it("reads several licenses in pom.xml as alternatives", func() {
+		writeFiles(t, dir, map[string]string{"pom.xml": `<project>
+  <artifactId>my-app</artifactId>
+  <licenses>
+    <license><name>The Apache Software License, Version 2.0</name></license>
+    <license><name>GPL-2.0-only</name></license>
+    <license><name>Apache-2.0</name></license>
+  </licenses>
+</project>`})
+
+		Expect(infer()).To(HaveKeyWithValue("org.opencontainers.image.licenses", "(Apache-2.0 OR GPL-2.0-only)"))
+	})
+
+	it("ignores licenses in pom.xml without an SPDX identifier", func() {
+		writeFiles(t, dir, map[string]string{"pom.xml": `<project>
+  <artifactId>my-app</artifactId>
+  <licenses>
+    <license><name>MIT License</name></license>
+    <license><name>Example Corp. Commercial License</name></license>
+  </licenses>
+</project>`})
+
+		Expect(infer()).To(HaveKeyWithValue("org.opencontainers.image.licenses", "MIT"))
+		Expect(buf.String()).To(ContainSubstring(`Ignoring license "Example Corp. Commercial License" in pom.xml without an SPDX identifier, set $BP_OCI_LICENSES instead`))
+
+		writeFiles(t, dir, map[string]string{"pom.xml": `<project>
+  <artifactId>my-app</artifactId>
+  <licenses><license><name>Example Corp. Commercial License</name></license></licenses>
+</project>`})
+
+		Expect(infer()).NotTo(HaveKey("org.opencontainers.image.licenses"))
+	})
+
+	it("reads pyproject.toml", func() {
+		writeFiles(t, dir, map[string]string{"pyproject.toml": `
+[project]
+name = "my-app"
+version = "1.2.3"
+description = "An application"
+license = { text = "BSD-3-Clause" }
+authors = [{ name = "Jane Doe", email = "jane@example.com" }]
+
+[project.urls]
+homepage = "https://example.com"
+Repository = "https://github.com/example/my-app"
+Documentation = "https://docs.example.com"
+`})
+
+		Expect(infer()).To(Equal(map[string]string{
+			"org.opencontainers.image.title":         "my-app",
+			"org.opencontainers.image.version":       "1.2.3",
+			"org.opencontainers.image.description":   "An application",
+			"org.opencontainers.image.licenses":      "BSD-3-Clause",
+			"org.opencontainers.image.authors":       "Jane Doe <jane@example.com>",
+			"org.opencontainers.image.url":           "https://example.com",
+			"org.opencontainers.image.source":        "https://github.com/example/my-app",
+			"org.opencontainers.image.documentation": "https://docs.example.com",
+		}))
+	})
+
+	it("reads poetry pyproject.toml", func() {
+		writeFiles(t, dir, map[string]string{"pyproject.toml": `
+[tool.poetry]
+name = "my-app"
+version = "1.2.3"
+authors = ["Jane Doe <jane@example.com>"]
+repository = "https://github.com/example/my-app"
+`})
+
+		Expect(infer()).To(Equal(map[string]string{
+			"org.opencontainers.image.title":   "my-app",
+			"org.opencontainers.image.version": "1.2.3",
+			"org.opencontainers.image.authors": "Jane Doe <jane@example.com>",
+			"org.opencontainers.image.source":  "https://github.com/example/my-app",
+		}))
+	})
+
+	it("reads Cargo.toml", func() {
+		writeFiles(t, dir, map[string]string{"Cargo.toml": `
+[package]
+name = "my-app"
+version.workspace = true
+description = "An application"
+license = "MIT OR Apache-2.0"
+authors = ["Jane Doe <jane@example.com>", "John Doe"]
+repository = "https://github.com/example/my-app"
+documentation = "https://docs.rs/my-app"
+`})
+
+		Expect(infer()).To(Equal(map[string]string{
+			"org.opencontainers.image.title":         "my-app",
+			"org.opencontainers.image.description":   "An application",
+			"org.opencontainers.image.licenses":      "MIT OR Apache-2.0",
+			"org.opencontainers.image.authors":       "Jane Doe <jane@example.com>, John Doe",
+			"org.opencontainers.image.source":        "https://github.com/example/my-app",
+			"org.opencontainers.image.documentation": "https://docs.rs/my-app",
+		}))
+	})
+
+	it("reads composer.json", func() {
+		writeFiles(t, dir, map[string]string{"composer.json": `{
+  "name": "example/my-app",
+  "description": "An application",
+  "license": ["MIT", "GPL-3.0-or-later"],
+  "authors": [{"name": "Jane Doe", "email": "jane@example.com"}],
+  "homepage": "https://example.com",
+  "support": {"source": "https://github.com/example/my-app", "docs": "https://docs.example.com"}
+}`})
+
+		Expect(infer()).To(Equal(map[string]string{
+			"org.opencontainers.image.title":         "example/my-app",
+			"org.opencontainers.image.description":   "An application",
+			"org.opencontainers.image.licenses":      "(MIT OR GPL-3.0-or-later)",
+			"org.opencontainers.image.authors":       "Jane Doe <jane@example.com>",
+			"org.opencontainers.image.url":           "https://example.com",
+			"org.opencontainers.image.source":        "https://github.com/example/my-app",
+			"org.opencontainers.image.documentation": "https://docs.example.com",
+		}))
+	})
+
+	it("reads go.mod", func() {
+		writeFiles(t, dir, map[string]string{"go.mod": "// comment\nmodule github.com/example/my-app/v4 // trailing\n\ngo 1.24\n"})
+
+		Expect(infer()).To(Equal(map[string]string{
+			"org.opencontainers.image.title":  "my-app",
+			"org.opencontainers.image.source": "https://github.com/example/my-app",
+		}))
+	})
+
+	it("prefers earlier manifests", func() {
+		writeFiles(t, dir, map[string]string{
+			"package.json": `{"name": "from-npm"}`,
+			"go.mod":       "module github.com/example/from-go\n",
+		})
+
+		inferred, err := labels.InferLabels(dir, log.NewPaketoLogger(buf))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(inferred).To(Equal([]labels.InferredLabel{
+			{Key: "org.opencontainers.image.title", Value: "from-npm", Source: "package.json"},
+			{Key: "org.opencontainers.image.source", Value: "https://github.com/example/from-go", Source: "go.mod"},
+		}))
+	})
+
+	it("fails on an invalid manifest", func() {
+		writeFiles(t, dir, map[string]string{"package.json": `{`})
+
+		_, err := labels.InferLabels(dir, log.NewPaketoLogger(buf))
+		Expect(err).To(MatchError(ContainSubstring("unable to parse")))
+	})
+}
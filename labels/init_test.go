@@ -25,7 +25,26 @@ import (
 
 func TestUnit(t *testing.T) {
 	suite := spec.New("labels", spec.Report(report.Terminal{}))
+	suite("Authors", testAuthors)
 	suite("Build", testBuild)
+	suite("CI", testCI)
+	suite("Conflicts", testConflicts)
+	suite("Created", testCreated)
+	suite("Defaults", testDefaults)
 	suite("Detect", testDetect)
+	suite("Dockerfile", testDockerfile)
+	suite("Environment", testEnvironment)
+	suite("File", testFile)
+	suite("Format", testFormat)
+	suite("Git", testGit)
+	suite("Infer", testInfer)
+	suite("License", testLicense)
+	suite("Plan", testPlan)
+	suite("Policy", testPolicy)
+	suite("Provenance", testProvenance)
+	suite("Readme", testReadme)
+	suite("Runtime", testRuntime)
+	suite("SPDX", testSPDX)
+	suite("Validate", testValidate)
 	suite.Run(t)
 }
@@ -0,0 +1,95 @@
+/*
+ * Copyright 2018-2025 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package labels
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// isJSONObject reports whether input is meant to be read as a JSON object, which is whenever its first character
+// other than whitespace is '{'.
+func isJSONObject(input string) bool {
+	return strings.HasPrefix(strings.TrimLeft(input, " \t\r\n"), "{")
+}
+
+// parseJSONLabels reads a JSON object of strings to strings, returning the labels in the order they appear,
+// including any duplicate keys. Values are expanded by ParseLabelList.
+func parseJSONLabels(input string) ([]labelPair, error) {
+	d := json.NewDecoder(strings.NewReader(input))
+
+	if _, err := d.Token(); err != nil {
+		return nil, fmt.Errorf("unable to decode JSON labels\n%w", err)
+	}
+
+	var labels []labelPair
+	for d.More() {
+		t, err := d.Token()
+		if err != nil {
+			return nil, fmt.Errorf("unable to decode JSON labels\n%w", err)
+		}
+
+		key, _ := t.(string)
+		if key == "" {
+			return nil, fmt.Errorf("unable to have an empty key in JSON labels")
+		}
+
+		var raw json.RawMessage
+		if err := d.Decode(&raw); err != nil {
+			return nil, fmt.Errorf("unable to decode JSON value of label %s\n%w", key, err)
+		}
+
+		if raw[0] != '"' {
+			return nil, fmt.Errorf("unable to use %s as the value of label %s, JSON values must be strings", jsonType(raw), key)
+		}
+
+		var value string
+		if err := json.Unmarshal(raw, &value); err != nil {
+			return nil, fmt.Errorf("unable to decode JSON value of label %s\n%w", key, err)
+		}
+
+		labels = append(labels, labelPair{Key: key, Value: value})
+	}
+
+	if _, err := d.Token(); err != nil {
+		return nil, fmt.Errorf("unable to decode JSON labels\n%w", err)
+	}
+
+	if _, err := d.Token(); !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("unable to have anything after the JSON labels object")
+	}
+
+	return labels, nil
+}
+
+func jsonType(raw json.RawMessage) string {
+	switch raw[0] {
+	case '{':
+		return "an object"
+	case '[':
+		return "an array"
+	case 't', 'f':
+		return "a boolean"
+	case 'n':
+		return "null"
+	default:
+		return "a number"
+	}
+}
@@ -16,6 +16,8 @@
 
 package labels
 
+import "sort"
+
 var Labels = map[string]string{
 	"BP_OCI_AUTHORS":       "org.opencontainers.image.authors",
 	"BP_OCI_CREATED":       "org.opencontainers.image.created",
@@ -30,3 +32,12 @@ var Labels = map[string]string{
 	"BP_OCI_VENDOR":        "org.opencontainers.image.vendor",
 	"BP_OCI_VERSION":       "org.opencontainers.image.version",
 }
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
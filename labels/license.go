@@ -0,0 +1,297 @@
+/*
+ * Copyright 2018-2025 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package labels
+
+import (
+	"embed"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/paketo-buildpacks/libpak/v2/log"
+)
+
+// LicenseConfidence is the fraction of a license text that must be found in a file for the license to be identified,
+// and the fraction of the file that must be made up of the licenses identified in it.
+const LicenseConfidence = 0.9
+
+// LicenseFilePatterns are the patterns, matched case-insensitively, of the files in the root of an application that
+// are scanned for license texts.
+var LicenseFilePatterns = []string{"LICENSE", "LICENSE.*", "LICENSE-*", "LICENCE", "LICENCE.*", "LICENCE-*",
+	"COPYING", "COPYING.*", "COPYING-*", "UNLICENSE"}
+
+//go:embed spdx/texts/*.txt
+var licenseTextFiles embed.FS
+
+// licenseVariants are the SPDX identifiers of licenses that share a text, keyed by the name of the text in spdx/texts.
+// The text alone cannot tell them apart.
+var licenseVariants = map[string][]string{
+	"GPL-2.0":  {"GPL-2.0-only", "GPL-2.0-or-later"},
+	"GPL-3.0":  {"GPL-3.0-only", "GPL-3.0-or-later"},
+	"LGPL-2.1": {"LGPL-2.1-only", "LGPL-2.1-or-later"},
+	"LGPL-3.0": {"LGPL-3.0-only", "LGPL-3.0-or-later"},
+}
+
+// copyrightLine matches the copyright notices in license files, which vary from one application to the next.
+var copyrightLine = regexp.MustCompile(`^[\s#*/>-]*(?:(?:copyright)?\s*(?:\(c\)|©)|copyright\s+[0-9<\[{])`)
+
+type licenseText struct {
+	ids     []string
+	bigrams map[string]bool
+}
+
+var licenseTexts = readLicenseTexts()
+
+func readLicenseTexts() []licenseText {
+	entries, err := licenseTextFiles.ReadDir("spdx/texts")
+	if err != nil {
+		panic(err)
+	}
+
+	var texts []licenseText
+	for _, e := range entries {
+		b, err := licenseTextFiles.ReadFile(path.Join("spdx/texts", e.Name()))
+		if err != nil {
+			panic(err)
+		}
+
+		name := strings.TrimSuffix(e.Name(), ".txt")
+		ids, ok := licenseVariants[name]
+		if !ok {
+			ids = []string{name}
+		}
+
+		texts = append(texts, licenseText{ids: ids, bigrams: licenseBigrams(string(b))})
+	}
+
+	return texts
+}
+
+// licenseBigrams returns the pairs of adjacent words in a text, ignoring case, punctuation, layout and copyright
+// notices, so that the same license reformatted or with a different copyright holder has the same bigrams.
+func licenseBigrams(text string) map[string]bool {
+	var words []string
+	for _, line := range strings.Split(strings.ToLower(text), "\n") {
+		if copyrightLine.MatchString(line) {
+			continue
+		}
+
+		for _, w := range strings.FieldsFunc(line, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) }) {
+			switch w {
+			case "https":
+				w = "http"
+			case "licence":
+				w = "license"
+			}
+			words = append(words, w)
+		}
+	}
+
+	bigrams := make(map[string]bool)
+	for i := 1; i < len(words); i++ {
+		bigrams[words[i-1]+" "+words[i]] = true
+	}
+	return bigrams
+}
+
+// overlap returns the fraction of a that is also in b.
+func overlap(a map[string]bool, b map[string]bool) float64 {
+	if len(a) == 0 {
+		return 0
+	}
+
+	n := 0
+	for k := range a {
+		if b[k] {
+			n++
+		}
+	}
+	return float64(n) / float64(len(a))
+}
+
+// LicenseMatch is a license identified in a text.
+type LicenseMatch struct {
+	// IDs are the SPDX identifiers of the license. There is more than one when the text does not tell them apart,
+	// such as GPL-3.0-only and GPL-3.0-or-later.
+	IDs []string
+
+	// Confidence is the fraction of the license text found, from 0 to 1.
+	Confidence float64
+}
+
+// IdentifyLicenses returns the licenses found in a text with at least LicenseConfidence, highest confidence first. A
+// license whose text is contained in another license that is found, such as BSD-2-Clause in BSD-3-Clause, is not
+// returned. The second return value is the closest license, which is returned even if it is not found with enough
+// confidence. Nothing is returned as found if LicenseConfidence of the text is not made up of the licenses found, as
+// it is then not only a license.
+func IdentifyLicenses(text string) ([]LicenseMatch, LicenseMatch) {
+	bigrams := licenseBigrams(text)
+
+	type candidate struct {
+		licenseText
+		confidence float64
+	}
+
+	var candidates []candidate
+	for _, t := range licenseTexts {
+		candidates = append(candidates, candidate{licenseText: t, confidence: overlap(t.bigrams, bigrams)})
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].confidence != candidates[j].confidence {
+			return candidates[i].confidence > candidates[j].confidence
+		}
+		return len(candidates[i].bigrams) > len(candidates[j].bigrams)
+	})
+
+	var closest LicenseMatch
+	if len(candidates) > 0 {
+		closest = LicenseMatch{IDs: candidates[0].ids, Confidence: candidates[0].confidence}
+	}
+
+	var found []candidate
+	for _, c := range candidates {
+		if c.confidence >= LicenseConfidence {
+			found = append(found, c)
+		}
+	}
+
+	// Longer licenses first, so that a license contained in one of them is seen after it.
+	sort.SliceStable(found, func(i, j int) bool {
+		return len(found[i].bigrams) > len(found[j].bigrams)
+	})
+
+	var (
+		matches []LicenseMatch
+		covered = make(map[string]bool)
+		kept    []candidate
+	)
+	for _, c := range found {
+		contained := false
+		for _, k := range kept {
+			if overlap(c.bigrams, k.bigrams) >= LicenseConfidence {
+				contained = true
+				break
+			}
+		}
+		if contained {
+			continue
+		}
+
+		kept = append(kept, c)
+		matches = append(matches, LicenseMatch{IDs: c.ids, Confidence: c.confidence})
+		for k := range c.bigrams {
+			covered[k] = true
+		}
+	}
+
+	if len(matches) == 0 || overlap(bigrams, covered) < LicenseConfidence {
+		return nil, closest
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].Confidence > matches[j].Confidence
+	})
+	return matches, closest
+}
+
+// FindLicenseFiles returns the names of the files in the root of an application that match LicenseFilePatterns,
+// sorted by name.
+func FindLicenseFiles(applicationPath string) ([]string, error) {
+	entries, err := os.ReadDir(applicationPath)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read %s\n%w", applicationPath, err)
+	}
+
+	var files []string
+	for _, e := range entries {
+		if !e.Type().IsRegular() {
+			continue
+		}
+
+		for _, p := range LicenseFilePatterns {
+			if ok, _ := path.Match(strings.ToLower(p), strings.ToLower(e.Name())); ok {
+				files = append(files, e.Name())
+				break
+			}
+		}
+	}
+
+	return files, nil
+}
+
+// licenseLabel returns the SPDX identifier of the license identified in the license files of an application, and the
+// files it was identified in. Nothing is returned if any license file cannot be identified or could be more than one
+// license, or if different licenses are identified, as whether they apply together (AND) or as alternatives (OR),
+// such as the common LICENSE-APACHE and LICENSE-MIT layout, cannot be told from the files.
+func licenseLabel(applicationPath string, logger log.Logger) (string, []string, error) {
+	files, err := FindLicenseFiles(applicationPath)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if len(files) == 0 {
+		logger.Body("No license file found in application")
+		return "", nil, nil
+	}
+
+	var (
+		ids  []string
+		seen = make(map[string]bool)
+	)
+	for _, f := range files {
+		b, err := os.ReadFile(filepath.Join(applicationPath, f))
+		if err != nil {
+			return "", nil, fmt.Errorf("unable to read %s\n%w", f, err)
+		}
+
+		matches, closest := IdentifyLicenses(string(b))
+		if len(matches) == 0 {
+			logger.Bodyf("Unable to identify the license in %s, the closest is %s with %.0f%% confidence",
+				f, strings.Join(closest.IDs, " or "), closest.Confidence*100)
+			logger.Body("Not setting a license label, set $BP_OCI_LICENSES instead")
+			return "", nil, nil
+		}
+
+		for _, m := range matches {
+			if len(m.IDs) > 1 {
+				logger.Bodyf("The license in %s could be %s", f, strings.Join(m.IDs, " or "))
+				logger.Body("Not setting a license label, set $BP_OCI_LICENSES instead")
+				return "", nil, nil
+			}
+
+			logger.Bodyf("Identified %s in %s with %.0f%% confidence", m.IDs[0], f, m.Confidence*100)
+			if !seen[m.IDs[0]] {
+				ids = append(ids, m.IDs[0])
+				seen[m.IDs[0]] = true
+			}
+		}
+	}
+
+	if len(ids) > 1 {
+		logger.Bodyf("Identified %s in %s, which may apply together or as alternatives", strings.Join(ids, ", "), strings.Join(files, ", "))
+		logger.Body("Not setting a license label, set $BP_OCI_LICENSES instead, e.g. to " + strings.Join(ids, " OR ") + " or " + strings.Join(ids, " AND "))
+		return "", nil, nil
+	}
+
+	return ids[0], files, nil
+}
@@ -0,0 +1,147 @@
+/*
+ * Copyright 2018-2025 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package labels_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+
+	"github.com/paketo-buildpacks/image-labels/v4/labels"
+)
+
+// licenseText returns the embedded text of a license as an application would have it, with a copyright notice.
+func licenseText(t *testing.T, name string) string {
+	t.Helper()
+
+	b, err := os.ReadFile(filepath.Join("spdx", "texts", name+".txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return "Copyright (c) 2025 Example Corp.\n\n" + string(b)
+}
+
+func testLicense(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+	)
+
+	ids := func(matches []labels.LicenseMatch) []string {
+		var ids []string
+		for _, m := range matches {
+			ids = append(ids, strings.Join(m.IDs, "|"))
+		}
+		return ids
+	}
+
+	context("IdentifyLicenses", func() {
+		it("identifies a license", func() {
+			matches, _ := labels.IdentifyLicenses(licenseText(t, "Apache-2.0"))
+			Expect(matches).To(Equal([]labels.LicenseMatch{{IDs: []string{"Apache-2.0"}, Confidence: 1}}))
+		})
+
+		it("ignores case, punctuation, layout and copyright notices", func() {
+			text := "# The MIT Licence\n\nCopyright © 2019-2025 Someone Else <someone@example.com>\n\n" +
+				strings.ReplaceAll(strings.ToUpper(licenseText(t, "MIT")), ", ", " ;\n    ")
+
+			matches, _ := labels.IdentifyLicenses(text)
+			Expect(ids(matches)).To(Equal([]string{"MIT"}))
+			Expect(matches[0].Confidence).To(BeNumerically(">=", labels.LicenseConfidence))
+		})
+
+		it("identifies a license with small changes", func() {
+			text := strings.Replace(licenseText(t, "BSD-3-Clause"), "the copyright holder nor", "Example Corp. nor", 1)
+
+			matches, _ := labels.IdentifyLicenses(text)
+			Expect(ids(matches)).To(Equal([]string{"BSD-3-Clause"}))
+		})
+
+		it("does not identify a license contained in the license found", func() {
+			matches, _ := labels.IdentifyLicenses(licenseText(t, "BSD-3-Clause"))
+			Expect(ids(matches)).To(Equal([]string{"BSD-3-Clause"}))
+
+			matches, _ = labels.IdentifyLicenses(licenseText(t, "ISC"))
+			Expect(ids(matches)).To(Equal([]string{"ISC"}))
+		})
+
+		it("tells similar licenses apart", func() {
+			matches, _ := labels.IdentifyLicenses(licenseText(t, "BSD-2-Clause"))
+			Expect(ids(matches)).To(Equal([]string{"BSD-2-Clause"}))
+
+			matches, _ = labels.IdentifyLicenses(licenseText(t, "0BSD"))
+			Expect(ids(matches)).To(Equal([]string{"0BSD"}))
+		})
+
+		it("returns every identifier of a license text shared by several licenses", func() {
+			matches, _ := labels.IdentifyLicenses(licenseText(t, "GPL-3.0"))
+			Expect(ids(matches)).To(Equal([]string{"GPL-3.0-only|GPL-3.0-or-later"}))
+		})
+
+		it("identifies several licenses in a text", func() {
+			matches, _ := labels.IdentifyLicenses(licenseText(t, "MIT") + "\n---\n\n" + licenseText(t, "Apache-2.0"))
+			Expect(ids(matches)).To(ConsistOf("Apache-2.0", "MIT"))
+		})
+
+		it("does not identify a license with too little of its text", func() {
+			text := licenseText(t, "MIT")
+			matches, closest := labels.IdentifyLicenses(text[:len(text)/2])
+
+			Expect(matches).To(BeEmpty())
+			Expect(closest.IDs).To(Equal([]string{"MIT"}))
+			Expect(closest.Confidence).To(BeNumerically("<", labels.LicenseConfidence))
+		})
+
+		it("does not identify a license in a text that is not only a license", func() {
+			b, err := os.ReadFile(filepath.Join("..", "README.md"))
+			Expect(err).NotTo(HaveOccurred())
+
+			matches, closest := labels.IdentifyLicenses(licenseText(t, "MIT") + string(b))
+			Expect(matches).To(BeEmpty())
+			Expect(closest).To(Equal(labels.LicenseMatch{IDs: []string{"MIT"}, Confidence: 1}))
+		})
+
+		it("does not identify a license in other text", func() {
+			matches, _ := labels.IdentifyLicenses("All rights reserved.")
+			Expect(matches).To(BeEmpty())
+		})
+	})
+
+	context("FindLicenseFiles", func() {
+		it("returns license files sorted by name", func() {
+			dir := t.TempDir()
+			writeFiles(t, dir, map[string]string{
+				"COPYING":          "",
+				"License.md":       "",
+				"LICENSE-APACHE":   "",
+				"licence.txt":      "",
+				"UNLICENSE":        "",
+				"README.md":        "",
+				"LICENSES/MIT.txt": "",
+			})
+
+			Expect(labels.FindLicenseFiles(dir)).To(Equal([]string{"COPYING", "LICENSE-APACHE", "License.md", "UNLICENSE", "licence.txt"}))
+		})
+
+		it("returns nothing without license files", func() {
+			Expect(labels.FindLicenseFiles(t.TempDir())).To(BeEmpty())
+		})
+	})
+}
@@ -0,0 +1,170 @@
+/*
+ * Copyright 2018-2025 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package labels
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+var (
+	// ErrUnterminatedQuote is returned by ReadKey and ReadValue for a quoted key or value without a closing quote.
+	ErrUnterminatedQuote = errors.New("unable to find a closing quote")
+
+	// ErrCharactersAfterQuote is returned by ReadKey and ReadValue for a quoted key or value followed by other
+	// characters.
+	ErrCharactersAfterQuote = errors.New("unable to have characters after a trailing quote")
+
+	// ErrInvalidEscape is returned by Unescape for a \u escape sequence that is not followed by four hexadecimal
+	// digits.
+	ErrInvalidEscape = errors.New("unable to read escape sequence")
+
+	// ErrUnexpectedQuote is returned by ReadKey and ReadValue for a quote inside an unquoted key or value.
+	ErrUnexpectedQuote = errors.New("unable to have a quote inside an unquoted key or value, escape it with a backslash")
+
+	// ErrMissingEquals is wrapped by ParseLabels for a key that is followed by a tab or newline and more labels
+	// instead of an '='.
+	ErrMissingEquals = errors.New("unable to find an equals sign after the key")
+)
+
+// ParseErrorKind identifies the reason that ParseLabels failed.
+type ParseErrorKind int
+
+const (
+	// UnterminatedQuote is a quoted key or value without a closing quote.
+	UnterminatedQuote ParseErrorKind = iota + 1
+
+	// EmptyKey is a key with no characters, such as ""=value.
+	EmptyKey
+
+	// CharactersAfterQuote is a quoted key or value followed by other characters, such as "key"junk=value.
+	CharactersAfterQuote
+
+	// MissingEquals is a key that is followed by a tab or newline and more labels instead of an '=', such as
+	// "key\nother=value". A key at the end of the input has an empty value instead.
+	MissingEquals
+
+	// InvalidEscape is a \u escape sequence that is not followed by four hexadecimal digits.
+	InvalidEscape
+
+	// UnexpectedQuote is a quote inside an unquoted key or value, such as key=it"s.
+	UnexpectedQuote
+)
+
+// Code returns a stable identifier for the kind that is suitable for matching in scripts.
+func (k ParseErrorKind) Code() string {
+	switch k {
+	case UnterminatedQuote:
+		return "unterminated-quote"
+	case EmptyKey:
+		return "empty-key"
+	case CharactersAfterQuote:
+		return "characters-after-quote"
+	case MissingEquals:
+		return "missing-equals"
+	case InvalidEscape:
+		return "invalid-escape"
+	case UnexpectedQuote:
+		return "unexpected-quote"
+	default:
+		return "unknown"
+	}
+}
+
+func (k ParseErrorKind) String() string {
+	return k.Code()
+}
+
+// ParseError describes where and why ParseLabels failed.
+//
+// Offset is the 0-based byte offset in Input at which the failure was detected, and Line and Column are the 1-based
+// position of that offset, with Column counted in characters. End is the offset at which the key or value being read
+// ends, as reported by the error message of earlier releases, which may be past Offset. Key is the key being read when
+// the failure occurred, which is empty if the key itself could not be read.
+type ParseError struct {
+	Input  string
+	Offset int
+	End    int
+	Line   int
+	Column int
+	Key    string
+	Kind   ParseErrorKind
+	Err    error
+}
+
+func newParseError(input string, offset int, end int, key string, kind ParseErrorKind, err error) *ParseError {
+	offset = max(0, min(offset, len(input)))
+
+	start := strings.LastIndexByte(input[:offset], '\n') + 1
+
+	return &ParseError{
+		Input:  input,
+		Offset: offset,
+		End:    end,
+		Line:   strings.Count(input[:offset], "\n") + 1,
+		Column: utf8.RuneCountInString(input[start:offset]) + 1,
+		Key:    key,
+		Kind:   kind,
+		Err:    err,
+	}
+}
+
+func (e *ParseError) Error() string {
+	switch {
+	case e.Kind == EmptyKey:
+		return fmt.Sprintf("unable to have empty key ending at char %d", e.End)
+	case e.Key == "" || e.Kind == MissingEquals:
+		return fmt.Sprintf("unable to read key ending at char %d\n%s", e.End, e.Err)
+	default:
+		return fmt.Sprintf("unable to read value ending at char %d\n%s", e.End, e.Err)
+	}
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// Format returns the line of the input containing the failure, with a caret beneath the point of failure followed
+// by the reason, for display in the build log.
+//
+//	some-label="hello world
+//	                       ^ unable to find a closing quote (unterminated-quote)
+func (e *ParseError) Format() string {
+	start := strings.LastIndexByte(e.Input[:e.Offset], '\n') + 1
+	end := len(e.Input)
+	if i := strings.IndexByte(e.Input[e.Offset:], '\n'); i >= 0 {
+		end = e.Offset + i
+	}
+
+	var caret strings.Builder
+	for _, c := range e.Input[start:e.Offset] {
+		if c == '\t' {
+			caret.WriteRune('\t')
+		} else {
+			caret.WriteRune(' ')
+		}
+	}
+
+	reason := "unable to have an empty key"
+	if e.Err != nil {
+		reason = e.Err.Error()
+	}
+
+	return fmt.Sprintf("%s\n%s^ %s (%s)", e.Input[start:end], caret.String(), reason, e.Kind.Code())
+}
@@ -0,0 +1,78 @@
+/*
+ * Copyright 2018-2025 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package labels
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/buildpacks/libcnb/v2"
+)
+
+// PlanEntryName is the name of the build plan entry that this buildpack provides and that other buildpacks require
+// to contribute labels.
+const PlanEntryName = "image-labels"
+
+// PlanLabel is a label contributed by another buildpack through the labels metadata of an image-labels build plan
+// entry, such as
+//
+//	[[requires]]
+//	name = "image-labels"
+//
+//	[requires.metadata.labels]
+//	"com.example.runtime" = "jre-21"
+type PlanLabel struct {
+	Key   string
+	Value string
+
+	// Entry is the index of the contributing entry in the buildpack plan.
+	Entry int
+}
+
+// PlanLabels returns the labels from the image-labels entries of a buildpack plan, in entry order and then sorted by
+// key. Nested tables are flattened and scalar values converted to strings as they are by ReadLabelsFile.
+func PlanLabels(plan libcnb.BuildpackPlan) ([]PlanLabel, error) {
+	var labels []PlanLabel
+
+	for i, e := range plan.Entries {
+		if e.Name != PlanEntryName || e.Metadata["labels"] == nil {
+			continue
+		}
+
+		raw, ok := e.Metadata["labels"].(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("unable to use a %T as the labels of build plan entry %d", e.Metadata["labels"], i)
+		}
+
+		m := make(map[string]string)
+		if err := flattenLabels("", raw, m); err != nil {
+			return nil, fmt.Errorf("unable to read labels from build plan entry %d\n%w", i, err)
+		}
+
+		keys := make([]string, 0, len(m))
+		for k := range m {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			labels = append(labels, PlanLabel{Key: k, Value: m[k], Entry: i})
+		}
+	}
+
+	return labels, nil
+}
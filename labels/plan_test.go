@@ -0,0 +1,65 @@
+/*
+ * Copyright 2018-2025 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package labels_test
+
+import (
+	"testing"
+
+	"github.com/buildpacks/libcnb/v2"
+	. "github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+
+	"github.com/paketo-buildpacks/image-labels/v4/labels"
+)
+
+func testPlan(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+	)
+
+	it("returns labels in entry and key order", func() {
+		Expect(labels.PlanLabels(libcnb.BuildpackPlan{Entries: []libcnb.BuildpackPlanEntry{
+			{Name: "image-labels", Metadata: map[string]interface{}{
+				"labels": map[string]interface{}{"com.example.tier": "web", "com.example.port": int64(8080)},
+			}},
+			{Name: "node", Metadata: map[string]interface{}{
+				"labels": map[string]interface{}{"com.example.ignored": "true"},
+			}},
+			{Name: "image-labels"},
+			{Name: "image-labels", Metadata: map[string]interface{}{
+				"labels": map[string]interface{}{"com": map[string]interface{}{"example": map[string]interface{}{"runtime": "node-22"}}},
+			}},
+		}})).To(Equal([]labels.PlanLabel{
+			{Key: "com.example.port", Value: "8080", Entry: 0},
+			{Key: "com.example.tier", Value: "web", Entry: 0},
+			{Key: "com.example.runtime", Value: "node-22", Entry: 3},
+		}))
+	})
+
+	it("returns no labels for an empty plan", func() {
+		Expect(labels.PlanLabels(libcnb.BuildpackPlan{})).To(BeEmpty())
+	})
+
+	it("fails on a list", func() {
+		_, err := labels.PlanLabels(libcnb.BuildpackPlan{Entries: []libcnb.BuildpackPlanEntry{
+			{Name: "image-labels", Metadata: map[string]interface{}{
+				"labels": map[string]interface{}{"com.example.tiers": []interface{}{"web", "api"}},
+			}},
+		}})
+		Expect(err).To(MatchError("unable to read labels from build plan entry 0\nunable to use a list as the value of com.example.tiers"))
+	})
+}
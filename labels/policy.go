@@ -0,0 +1,179 @@
+/*
+ * Copyright 2018-2025 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package labels
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+
+	"github.com/buildpacks/libcnb/v2"
+	"github.com/paketo-buildpacks/libpak/v2"
+)
+
+// PolicyMetadataKey is the key of the table in the [metadata] of buildpack.toml that builder authors can declare a
+// label policy in.
+const PolicyMetadataKey = "label-policy"
+
+// Policy declares the labels that an image must have, the values they may have and the labels it must not have.
+// A policy is written in TOML, YAML or JSON as
+//
+//	required = ["org.opencontainers.image.source", "com.example.owner"]
+//	forbidden = ["com.example.internal.*"]
+//
+//	[allowed]
+//	"org.opencontainers.image.vendor" = "Example( Inc\\.)?"
+type Policy struct {
+	// Source names where the policy was read from.
+	Source string `json:"-"`
+
+	// Required are the keys of labels that must be set to a non-empty value.
+	Required []string `json:"required"`
+
+	// Allowed maps keys to regular expressions that the whole value of the label must match, if it is set.
+	Allowed map[string]string `json:"allowed"`
+
+	// Forbidden are patterns, in the syntax of path.Match, of keys of labels that must not be set.
+	Forbidden []string `json:"forbidden"`
+
+	allowed map[string]*regexp.Regexp
+}
+
+// NewPolicy creates a Policy from the decoded contents of a policy, rejecting unknown fields, invalid regular
+// expressions and invalid patterns.
+func NewPolicy(source string, raw map[string]interface{}) (Policy, error) {
+	b, err := json.Marshal(raw)
+	if err != nil {
+		return Policy{}, fmt.Errorf("unable to encode label policy from %s\n%w", source, err)
+	}
+
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.DisallowUnknownFields()
+
+	p := Policy{Source: source}
+	if err := d.Decode(&p); err != nil {
+		return Policy{}, fmt.Errorf("unable to decode label policy from %s\n%w", source, err)
+	}
+
+	p.allowed = make(map[string]*regexp.Regexp, len(p.Allowed))
+	for k, v := range p.Allowed {
+		if p.allowed[k], err = regexp.Compile(`^(?:` + v + `)$`); err != nil {
+			return Policy{}, fmt.Errorf("unable to compile the allowed values of %s in label policy from %s\n%w", k, source, err)
+		}
+	}
+
+	for _, f := range p.Forbidden {
+		if _, err := path.Match(f, ""); err != nil {
+			return Policy{}, fmt.Errorf("unable to use forbidden pattern %q in label policy from %s\n%w", f, source, err)
+		}
+	}
+
+	return p, nil
+}
+
+// ReadPolicyFile reads a Policy from a TOML, YAML or JSON file, chosen by the file extension.
+func ReadPolicyFile(file string) (Policy, error) {
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return Policy{}, fmt.Errorf("unable to read %s\n%w", file, err)
+	}
+
+	raw := map[string]interface{}{}
+	if err := decodeFile(file, b, &raw); err != nil {
+		return Policy{}, err
+	}
+
+	return NewPolicy(file, raw)
+}
+
+// ResolvePolicies returns the policy declared in the buildpack metadata by the builder author, followed by the policy
+// in the file that $BP_IMAGE_LABELS_POLICY points at, resolved relative to the application path. Each policy that
+// is present must be satisfied.
+func ResolvePolicies(applicationPath string, metadata map[string]interface{}, cr libpak.ConfigurationResolver) ([]Policy, error) {
+	var policies []Policy
+
+	if raw, ok := metadata[PolicyMetadataKey]; ok {
+		m, ok := raw.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("unable to use a %T as the label policy in buildpack.toml", raw)
+		}
+
+		p, err := NewPolicy("buildpack.toml", m)
+		if err != nil {
+			return nil, err
+		}
+		policies = append(policies, p)
+	}
+
+	if s, ok := cr.Resolve("BP_IMAGE_LABELS_POLICY"); ok && s != "" {
+		if !filepath.IsAbs(s) {
+			s = filepath.Join(applicationPath, s)
+		}
+
+		p, err := ReadPolicyFile(s)
+		if err != nil {
+			return nil, err
+		}
+		policies = append(policies, p)
+	}
+
+	return policies, nil
+}
+
+// Violations returns a description of every way in which labels do not satisfy the policy.
+func (p Policy) Violations(labels []libcnb.Label) []string {
+	var violations []string
+
+	values := make(map[string]string, len(labels))
+	for _, l := range labels {
+		values[l.Key] = l.Value
+	}
+
+	for _, k := range p.Required {
+		if values[k] == "" {
+			violations = append(violations, fmt.Sprintf("%s: required label %s is not set", p.Source, k))
+		}
+	}
+
+	keys := make([]string, 0, len(p.allowed))
+	for k := range p.allowed {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		if v, ok := values[k]; ok && !p.allowed[k].MatchString(v) {
+			violations = append(violations, fmt.Sprintf("%s: label %s=%q does not match %s", p.Source, k, v, p.Allowed[k]))
+		}
+	}
+
+	for _, l := range labels {
+		for _, f := range p.Forbidden {
+			if ok, _ := path.Match(f, l.Key); ok {
+				violations = append(violations, fmt.Sprintf("%s: label %s is forbidden by %s", p.Source, l.Key, f))
+				break
+			}
+		}
+	}
+
+	return violations
+}
@@ -0,0 +1,127 @@
+/*
+ * Copyright 2018-2025 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package labels_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/buildpacks/libcnb/v2"
+	. "github.com/onsi/gomega"
+	"github.com/paketo-buildpacks/libpak/v2"
+	"github.com/sclevine/spec"
+
+	"github.com/paketo-buildpacks/image-labels/v4/labels"
+)
+
+func testPolicy(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		cr  libpak.ConfigurationResolver
+		dir string
+	)
+
+	it.Before(func() {
+		dir = t.TempDir()
+	})
+
+	context("NewPolicy", func() {
+		it("rejects unknown fields", func() {
+			_, err := labels.NewPolicy("test", map[string]interface{}{"require": []interface{}{"alpha"}})
+			Expect(err).To(MatchError(ContainSubstring(`unknown field "require"`)))
+		})
+
+		it("rejects invalid regular expressions", func() {
+			_, err := labels.NewPolicy("test", map[string]interface{}{"allowed": map[string]interface{}{"alpha": "("}})
+			Expect(err).To(MatchError(ContainSubstring("unable to compile the allowed values of alpha in label policy from test")))
+		})
+
+		it("rejects invalid patterns", func() {
+			_, err := labels.NewPolicy("test", map[string]interface{}{"forbidden": []interface{}{"alpha["}})
+			Expect(err).To(MatchError(ContainSubstring(`unable to use forbidden pattern "alpha[" in label policy from test`)))
+		})
+	})
+
+	context("Violations", func() {
+		it("lists every violation", func() {
+			p, err := labels.NewPolicy("test", map[string]interface{}{
+				"required":  []interface{}{"org.opencontainers.image.source", "org.opencontainers.image.revision", "com.example.owner"},
+				"allowed":   map[string]interface{}{"org.opencontainers.image.vendor": `Example( Inc\.)?`, "com.example.tier": "gold|silver"},
+				"forbidden": []interface{}{"com.example.internal.*"},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(p.Violations([]libcnb.Label{
+				{Key: "com.example.internal.cost-center", Value: "42"},
+				{Key: "com.example.owner", Value: ""},
+				{Key: "com.example.tier", Value: "gold"},
+				{Key: "org.opencontainers.image.revision", Value: "abc123"},
+				{Key: "org.opencontainers.image.vendor", Value: "Example Incorporated"},
+			})).To(Equal([]string{
+				"test: required label org.opencontainers.image.source is not set",
+				"test: required label com.example.owner is not set",
+				`test: label org.opencontainers.image.vendor="Example Incorporated" does not match Example( Inc\.)?`,
+				"test: label com.example.internal.cost-center is forbidden by com.example.internal.*",
+			}))
+		})
+
+		it("has no violations for matching labels", func() {
+			p, err := labels.NewPolicy("test", map[string]interface{}{
+				"required": []interface{}{"alpha"},
+				"allowed":  map[string]interface{}{"alpha": "b.*"},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(p.Violations([]libcnb.Label{{Key: "alpha", Value: "bravo"}})).To(BeEmpty())
+		})
+	})
+
+	context("ResolvePolicies", func() {
+		it("has no policies by default", func() {
+			Expect(labels.ResolvePolicies(dir, nil, cr)).To(BeEmpty())
+		})
+
+		it("reads a policy from the buildpack metadata and $BP_IMAGE_LABELS_POLICY", func() {
+			Expect(os.WriteFile(filepath.Join(dir, "policy.yaml"), []byte("required:\n- com.example.owner\n"), 0644)).To(Succeed())
+			t.Setenv("BP_IMAGE_LABELS_POLICY", "policy.yaml")
+
+			policies, err := labels.ResolvePolicies(dir, map[string]interface{}{
+				"label-policy": map[string]interface{}{"forbidden": []interface{}{"com.example.internal.*"}},
+			}, cr)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(policies).To(HaveLen(2))
+			Expect(policies[0].Source).To(Equal("buildpack.toml"))
+			Expect(policies[0].Forbidden).To(Equal([]string{"com.example.internal.*"}))
+			Expect(policies[1].Source).To(Equal(filepath.Join(dir, "policy.yaml")))
+			Expect(policies[1].Required).To(Equal([]string{"com.example.owner"}))
+		})
+
+		it("fails if $BP_IMAGE_LABELS_POLICY does not exist", func() {
+			t.Setenv("BP_IMAGE_LABELS_POLICY", "policy.toml")
+
+			_, err := labels.ResolvePolicies(dir, nil, cr)
+			Expect(err).To(MatchError(ContainSubstring("unable to read")))
+		})
+
+		it("fails on invalid buildpack metadata", func() {
+			_, err := labels.ResolvePolicies(dir, map[string]interface{}{"label-policy": "strict"}, cr)
+			Expect(err).To(MatchError("unable to use a string as the label policy in buildpack.toml"))
+		})
+	})
+}
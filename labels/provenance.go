@@ -0,0 +1,137 @@
+/*
+ * Copyright 2018-2025 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package labels
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/buildpacks/libcnb/v2"
+	"github.com/paketo-buildpacks/libpak/v2/log"
+)
+
+const (
+	// OriginEnvironment is the kind of a label set by an environment variable.
+	OriginEnvironment = "environment"
+
+	// OriginFile is the kind of a label read from a labels file or Dockerfile.
+	OriginFile = "file"
+
+	// OriginBuildPlan is the kind of a label contributed by another buildpack through the build plan.
+	OriginBuildPlan = "build-plan"
+
+	// OriginCI is the kind of a label derived from the environment of a CI provider.
+	OriginCI = "ci"
+
+	// OriginGit is the kind of a label read from git metadata.
+	OriginGit = "git"
+
+	// OriginInferred is the kind of a label inferred from a package manifest.
+	OriginInferred = "inferred"
+
+	// OriginDefault is the kind of a default label declared by the builder in buildpack.toml.
+	OriginDefault = "default"
+)
+
+// Origin describes where the value of a label came from.
+type Origin struct {
+	// Kind is one of OriginEnvironment, OriginFile, OriginBuildPlan, OriginCI, OriginGit, OriginInferred or
+	// OriginDefault.
+	Kind string `json:"kind"`
+
+	// Name identifies the source within its kind: the name of an environment variable, the path of a labels file,
+	// Dockerfile or manifest, the index of a build plan entry, the CI provider, the git directory or buildpack.toml.
+	Name string `json:"name"`
+
+	// Line is the line of a file that the label is set on, or zero if it is not known.
+	Line int `json:"line,omitempty"`
+}
+
+// String returns the origin as it is shown in the build log.
+func (o Origin) String() string {
+	switch {
+	case o.Kind == OriginEnvironment:
+		return "$" + o.Name
+	case o.Kind == OriginBuildPlan:
+		return "build plan entry " + o.Name
+	case o.Line > 0:
+		return fmt.Sprintf("%s:%d", o.Name, o.Line)
+	default:
+		return o.Name
+	}
+}
+
+// Provenance is a label and the origin of its value.
+type Provenance struct {
+	Key    string `json:"key"`
+	Value  string `json:"value"`
+	Origin Origin `json:"origin"`
+}
+
+// ProvenanceLayerName is the name of the build-only layer that the provenance report is written to.
+const ProvenanceLayerName = "provenance"
+
+// ProvenanceFile is the name of the provenance report in its layer.
+const ProvenanceFile = "provenance.json"
+
+// LogProvenance logs a table of labels and their origins.
+func LogProvenance(provenance []Provenance, logger log.Logger) {
+	width := len("Label")
+	for _, p := range provenance {
+		if len(p.Key) > width {
+			width = len(p.Key)
+		}
+	}
+
+	logger.Header("Label provenance")
+	logger.Bodyf("%-*s  %s", width, "Label", "Origin")
+	logger.Bodyf("%s  %s", strings.Repeat("-", width), strings.Repeat("-", len("Origin")))
+	for _, p := range provenance {
+		logger.Bodyf("%-*s  %s", width, p.Key, p.Origin)
+	}
+}
+
+// ContributeProvenance writes the provenance report as JSON to a build-only layer, so that it can be archived by
+// the platform, and returns the layer.
+func ContributeProvenance(layers libcnb.Layers, provenance []Provenance) (libcnb.Layer, error) {
+	layer, err := layers.Layer(ProvenanceLayerName)
+	if err != nil {
+		return libcnb.Layer{}, fmt.Errorf("unable to create layer %s\n%w", ProvenanceLayerName, err)
+	}
+
+	if layer, err = layer.Reset(); err != nil {
+		return libcnb.Layer{}, fmt.Errorf("unable to reset layer %s\n%w", ProvenanceLayerName, err)
+	}
+	layer.LayerTypes = libcnb.LayerTypes{Build: true}
+
+	b, err := json.MarshalIndent(struct {
+		Labels []Provenance `json:"labels"`
+	}{provenance}, "", "  ")
+	if err != nil {
+		return libcnb.Layer{}, fmt.Errorf("unable to encode label provenance\n%w", err)
+	}
+
+	file := filepath.Join(layer.Path, ProvenanceFile)
+	if err := os.WriteFile(file, append(b, '\n'), 0644); err != nil {
+		return libcnb.Layer{}, fmt.Errorf("unable to write %s\n%w", file, err)
+	}
+
+	return layer, nil
+}
@@ -0,0 +1,92 @@
+/*
+ * Copyright 2018-2025 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package labels_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/buildpacks/libcnb/v2"
+	. "github.com/onsi/gomega"
+	"github.com/paketo-buildpacks/libpak/v2/log"
+	"github.com/sclevine/spec"
+
+	"github.com/paketo-buildpacks/image-labels/v4/labels"
+)
+
+func testProvenance(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		provenance = []labels.Provenance{
+			{Key: "com.example.team", Value: "platform", Origin: labels.Origin{Kind: labels.OriginFile, Name: "/workspace/labels.toml", Line: 2}},
+			{Key: "org.opencontainers.image.title", Value: "my app", Origin: labels.Origin{Kind: labels.OriginEnvironment, Name: "BP_OCI_TITLE"}},
+		}
+	)
+
+	context("Origin", func() {
+		it("formats origins", func() {
+			Expect(labels.Origin{Kind: labels.OriginEnvironment, Name: "BP_IMAGE_LABELS"}.String()).To(Equal("$BP_IMAGE_LABELS"))
+			Expect(labels.Origin{Kind: labels.OriginFile, Name: "labels.toml", Line: 3}.String()).To(Equal("labels.toml:3"))
+			Expect(labels.Origin{Kind: labels.OriginFile, Name: "labels.toml"}.String()).To(Equal("labels.toml"))
+			Expect(labels.Origin{Kind: labels.OriginBuildPlan, Name: "1"}.String()).To(Equal("build plan entry 1"))
+			Expect(labels.Origin{Kind: labels.OriginInferred, Name: "package.json"}.String()).To(Equal("package.json"))
+		})
+	})
+
+	context("LogProvenance", func() {
+		it("logs a table", func() {
+			buf := &bytes.Buffer{}
+			labels.LogProvenance(provenance, log.NewPaketoLogger(buf))
+
+			Expect(buf.String()).To(ContainSubstring("Label provenance"))
+			Expect(buf.String()).To(ContainSubstring("Label                           Origin"))
+			Expect(buf.String()).To(ContainSubstring("com.example.team                /workspace/labels.toml:2"))
+			Expect(buf.String()).To(ContainSubstring("org.opencontainers.image.title  $BP_OCI_TITLE"))
+		})
+	})
+
+	context("ContributeProvenance", func() {
+		it("writes a build-only layer", func() {
+			layers := libcnb.Layers{Path: t.TempDir()}
+
+			layer, err := labels.ContributeProvenance(layers, provenance)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(layer.Name).To(Equal("provenance"))
+			Expect(layer.LayerTypes).To(Equal(libcnb.LayerTypes{Build: true}))
+
+			b, err := os.ReadFile(filepath.Join(layers.Path, "provenance", "provenance.json"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(b).To(MatchJSON(`{"labels": [
+				{"key": "com.example.team", "value": "platform", "origin": {"kind": "file", "name": "/workspace/labels.toml", "line": 2}},
+				{"key": "org.opencontainers.image.title", "value": "my app", "origin": {"kind": "environment", "name": "BP_OCI_TITLE"}}
+			]}`))
+		})
+
+		it("replaces an earlier report", func() {
+			layers := libcnb.Layers{Path: t.TempDir()}
+			Expect(os.MkdirAll(filepath.Join(layers.Path, "provenance"), 0755)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(layers.Path, "provenance", "stale.json"), []byte("{}"), 0644)).To(Succeed())
+
+			_, err := labels.ContributeProvenance(layers, provenance)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(filepath.Join(layers.Path, "provenance", "stale.json")).NotTo(BeAnExistingFile())
+		})
+	})
+}
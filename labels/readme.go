@@ -0,0 +1,399 @@
+/*
+ * Copyright 2018-2025 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package labels
+
+import (
+	"fmt"
+	"html"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/buildpacks/libcnb/v2"
+	"github.com/paketo-buildpacks/libpak/v2"
+	"github.com/paketo-buildpacks/libpak/v2/log"
+)
+
+// DefaultReadmeLength is the maximum length, in characters, of a description read from a README when
+// $BP_IMAGE_LABELS_README_LENGTH is not set.
+const DefaultReadmeLength = 256
+
+// markdownEscaped is the start of the private use block of code points that escaped ASCII characters are moved to
+// while Markdown is stripped.
+const markdownEscaped = 0xE000
+
+// markdownFrontMatter are the delimiters of YAML and TOML front matter at the start of a Markdown file.
+var markdownFrontMatter = []string{"---", "+++"}
+
+// ReadmeNames are the names, matched case-insensitively, of the README files that a title and description are read
+// from, in order of precedence.
+var ReadmeNames = []string{"README.md", "README.markdown", "README.rst", "README.txt", "README"}
+
+// Readme is the title and description of an application read from its README.
+type Readme struct {
+	// Title is the text of the first heading.
+	Title string
+
+	// Description is the text of the first paragraph of prose, without markup and with whitespace collapsed.
+	Description string
+}
+
+// ResolveReadmeLength returns the maximum length of a description configured with $BP_IMAGE_LABELS_README_LENGTH,
+// where 0 means no maximum.
+func ResolveReadmeLength(cr libpak.ConfigurationResolver) (int, error) {
+	s, ok := cr.Resolve("BP_IMAGE_LABELS_README_LENGTH")
+	if !ok || strings.TrimSpace(s) == "" {
+		return DefaultReadmeLength, nil
+	}
+
+	n, err := strconv.Atoi(strings.TrimSpace(s))
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("unsupported $BP_IMAGE_LABELS_README_LENGTH %q, must be a number of characters or 0", s)
+	}
+	return n, nil
+}
+
+// FindReadme returns the name of the README in the root of an application, the first of ReadmeNames found.
+func FindReadme(applicationPath string) (string, bool, error) {
+	return findFileFold(applicationPath, ReadmeNames)
+}
+
+// findFileFold returns the name of the first of names, matched case-insensitively, that is a file in a directory.
+func findFileFold(dir string, names []string) (string, bool, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", false, fmt.Errorf("unable to read %s\n%w", dir, err)
+	}
+
+	for _, n := range names {
+		for _, e := range entries {
+			if e.Type().IsRegular() && strings.EqualFold(e.Name(), n) {
+				return e.Name(), true, nil
+			}
+		}
+	}
+
+	return "", false, nil
+}
+
+// ParseReadme returns the title and description of a README, read as reStructuredText if its name ends with .rst and
+// as Markdown otherwise.
+func ParseReadme(name string, text string) Readme {
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	if strings.EqualFold(filepath.Ext(name), ".rst") {
+		return parseRST(lines)
+	}
+	return parseMarkdown(lines)
+}
+
+// TruncateDescription shortens a description to at most max characters, cutting it at the end of a word if
+// possible and marking the cut with an ellipsis. A max of 0 leaves the description as is.
+func TruncateDescription(s string, max int) string {
+	if max <= 0 || utf8.RuneCountInString(s) <= max {
+		return s
+	}
+
+	r := []rune(s)
+	cut := max - 1
+	if r[cut] != ' ' {
+		if i := strings.LastIndex(string(r[:cut]), " "); i > 0 && utf8.RuneCountInString(s[:i]) > max/2 {
+			cut = utf8.RuneCountInString(s[:i])
+		}
+	}
+
+	return strings.TrimRight(string(r[:cut]), " ,;:.-") + "…"
+}
+
+// readmeLabels returns the title and description labels read from an application's README, omitting any that
+// cannot be found, and the name of the README.
+func readmeLabels(applicationPath string, length int, logger log.Logger) ([]libcnb.Label, string, error) {
+	name, ok, err := FindReadme(applicationPath)
+	if err != nil {
+		return nil, "", err
+	} else if !ok {
+		logger.Body("No README found in application")
+		return nil, "", nil
+	}
+
+	b, err := os.ReadFile(filepath.Join(applicationPath, name))
+	if err != nil {
+		return nil, "", fmt.Errorf("unable to read %s\n%w", name, err)
+	}
+
+	r := ParseReadme(name, string(b))
+	if r.Title == "" && r.Description == "" {
+		logger.Bodyf("No title or description found in %s", name)
+		return nil, name, nil
+	}
+
+	var labels []libcnb.Label
+	for _, l := range []libcnb.Label{
+		{Key: Labels["BP_OCI_TITLE"], Value: r.Title},
+		{Key: Labels["BP_OCI_DESCRIPTION"], Value: TruncateDescription(r.Description, length)},
+	} {
+		if l.Value != "" {
+			labels = append(labels, l)
+		}
+	}
+
+	return labels, name, nil
+}
+
+var (
+	markdownATXHeading  = regexp.MustCompile(`^#{1,6}(?:\s+(.*?))?(?:\s+#+)?\s*$`)
+	markdownSetext      = regexp.MustCompile(`^(?:=+|-+)\s*$`)
+	markdownRule        = regexp.MustCompile(`^(?:(?:-\s*){3,}|(?:\*\s*){3,}|(?:_\s*){3,})$`)
+	markdownListItem    = regexp.MustCompile(`^(?:[-*+]|\d{1,9}[.)])(?:\s|$)`)
+	markdownLinkRef     = regexp.MustCompile(`^\[[^\]]+\]:`)
+	markdownAlert       = regexp.MustCompile(`^\[![A-Za-z]+\]$`)
+	markdownHTMLHeading = regexp.MustCompile(`(?i)<h[1-6][^>]*>(.*?)</h[1-6]>`)
+	markdownImage       = regexp.MustCompile(`!\[[^\]]*\](?:\([^)]*\)|\[[^\]]*\])`)
+	markdownLink        = regexp.MustCompile(`\[([^\]]*)\](?:\([^)]*\)|\[[^\]]*\])`)
+	markdownAutolink    = regexp.MustCompile(`<((?:https?|mailto):[^>\s]+)>`)
+	markdownTag         = regexp.MustCompile(`</?[A-Za-z][^>]*>`)
+	markdownEmphasis    = regexp.MustCompile("\\*+|~~|`+")
+	markdownUnderscoreL = regexp.MustCompile(`(^|\W)_+(\w)`)
+	markdownUnderscoreR = regexp.MustCompile(`(\w)_+(\W|$)`)
+	markdownEscape      = regexp.MustCompile("\\\\([!-/:-@\\[-`{-~])")
+	rstListItem         = regexp.MustCompile(`^(?:[-*+•]|#\.|\d{1,9}\.|\(?[a-zA-Z0-9]\))\s`)
+	rstFieldList        = regexp.MustCompile(`^:[^:]+:(?:\s|$)`)
+	rstRole             = regexp.MustCompile(":[\\w:+.-]+:`([^`]*)`")
+	rstLiteral          = regexp.MustCompile("``([^`]*)``")
+	rstNamedLink        = regexp.MustCompile("`([^`<]*?)\\s*<[^>]*>`__?")
+	rstInterpreted      = regexp.MustCompile("`([^`]*)`(?:__?)?")
+	rstSubstitution     = regexp.MustCompile(`\|[^|\s][^|]*\|_{0,2}`)
+	rstReference        = regexp.MustCompile(`(\w)__?(\W|$)`)
+	rstEmphasis         = regexp.MustCompile(`\*+`)
+)
+
+// parseMarkdown reads the first ATX, setext or HTML heading and the first paragraph that is not empty once markup is
+// removed, so that paragraphs of badges are skipped. Front matter, code blocks, lists, tables and comments are not
+// prose and are skipped.
+func parseMarkdown(lines []string) Readme {
+	var (
+		r         Readme
+		paragraph []string
+		fence     string
+		comment   bool
+	)
+
+	flush := func() {
+		if r.Description == "" && len(paragraph) > 0 {
+			r.Description = stripMarkdown(strings.Join(paragraph, " "))
+		}
+		paragraph = nil
+	}
+
+	i := 0
+	if len(lines) > 0 {
+		for _, f := range markdownFrontMatter {
+			if strings.TrimSpace(lines[0]) != f {
+				continue
+			}
+			for j := 1; j < len(lines); j++ {
+				if strings.TrimSpace(lines[j]) == f {
+					i = j + 1
+					break
+				}
+			}
+		}
+	}
+
+	for ; i < len(lines) && (r.Title == "" || r.Description == ""); i++ {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+
+		switch {
+		case fence != "":
+			if strings.HasPrefix(trimmed, fence) {
+				fence = ""
+			}
+
+		case comment:
+			comment = !strings.Contains(trimmed, "-->")
+
+		case strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~"):
+			flush()
+			fence = trimmed[:3]
+
+		case strings.HasPrefix(trimmed, "<!--"):
+			flush()
+			comment = !strings.Contains(trimmed, "-->")
+
+		case trimmed == "":
+			flush()
+
+		case len(paragraph) > 0 && markdownSetext.MatchString(trimmed):
+			if r.Title == "" {
+				r.Title = stripMarkdown(strings.Join(paragraph, " "))
+			}
+			paragraph = nil
+
+		case markdownRule.MatchString(trimmed):
+			flush()
+
+		case strings.HasPrefix(trimmed, "#"):
+			if m := markdownATXHeading.FindStringSubmatch(trimmed); m != nil {
+				flush()
+				if r.Title == "" {
+					r.Title = stripMarkdown(m[1])
+				}
+			} else {
+				paragraph = append(paragraph, trimmed)
+			}
+
+		case markdownHTMLHeading.MatchString(trimmed):
+			flush()
+			if r.Title == "" {
+				r.Title = stripMarkdown(markdownHTMLHeading.FindStringSubmatch(trimmed)[1])
+			}
+
+		case len(paragraph) == 0 && (strings.HasPrefix(line, "    ") || strings.HasPrefix(line, "\t")),
+			markdownListItem.MatchString(trimmed),
+			markdownLinkRef.MatchString(trimmed),
+			strings.HasPrefix(trimmed, "|"):
+			flush()
+
+		case strings.HasPrefix(trimmed, ">"):
+			if quote := strings.TrimSpace(strings.TrimLeft(trimmed, "> ")); !markdownAlert.MatchString(quote) {
+				paragraph = append(paragraph, quote)
+			}
+
+		default:
+			paragraph = append(paragraph, trimmed)
+		}
+	}
+	flush()
+
+	return r
+}
+
+// stripMarkdown returns the text of inline Markdown and HTML, dropping images and keeping the text of links.
+func stripMarkdown(s string) string {
+	// Escaped characters are moved out of ASCII, so that they are not read as markup, and restored at the end.
+	s = markdownEscape.ReplaceAllStringFunc(s, func(e string) string { return string(rune(markdownEscaped + int(e[1]))) })
+
+	s = markdownImage.ReplaceAllString(s, "")
+	s = markdownLink.ReplaceAllString(s, "$1")
+	s = markdownAutolink.ReplaceAllString(s, "$1")
+	s = markdownTag.ReplaceAllString(s, "")
+	s = markdownEmphasis.ReplaceAllString(s, "")
+	s = markdownUnderscoreL.ReplaceAllString(s, "$1$2")
+	s = markdownUnderscoreR.ReplaceAllString(s, "$1$2")
+	s = strings.Map(func(r rune) rune {
+		if r >= markdownEscaped && r < markdownEscaped+utf8.RuneSelf {
+			return r - markdownEscaped
+		}
+		return r
+	}, s)
+	return strings.Join(strings.Fields(html.UnescapeString(s)), " ")
+}
+
+// parseRST reads the first section title, underlined and optionally overlined, and the first paragraph that is not
+// empty once markup is removed. Directives, comments, literal blocks, lists and field lists are not prose and are
+// skipped.
+func parseRST(lines []string) Readme {
+	var (
+		r         Readme
+		paragraph []string
+		skip      bool
+	)
+
+	flush := func() {
+		if r.Description == "" && len(paragraph) > 0 {
+			r.Description = stripRST(strings.Join(paragraph, " "))
+		}
+		paragraph = nil
+	}
+
+	for i := 0; i < len(lines) && (r.Title == "" || r.Description == ""); i++ {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+
+		// The indented body of a directive, comment or literal block.
+		if skip {
+			if trimmed == "" || line[0] == ' ' || line[0] == '\t' {
+				continue
+			}
+			skip = false
+		}
+
+		switch {
+		case trimmed == "":
+			flush()
+
+		case strings.HasPrefix(trimmed, ".."):
+			flush()
+			skip = true
+
+		case isRSTAdornment(trimmed):
+			// An overline, followed by the title and its underline.
+			if len(paragraph) == 0 && i+2 < len(lines) && strings.TrimSpace(lines[i+2]) == trimmed {
+				if r.Title == "" {
+					r.Title = stripRST(lines[i+1])
+				}
+				i += 2
+			} else if len(paragraph) == 1 {
+				if r.Title == "" {
+					r.Title = stripRST(paragraph[0])
+				}
+				paragraph = nil
+			} else {
+				flush()
+			}
+
+		case len(paragraph) == 0 && (rstListItem.MatchString(trimmed) || rstFieldList.MatchString(trimmed)):
+			skip = true
+
+		case strings.HasSuffix(trimmed, "::"):
+			paragraph = append(paragraph, strings.TrimSuffix(trimmed, ":"))
+			flush()
+			skip = true
+
+		default:
+			paragraph = append(paragraph, trimmed)
+		}
+	}
+	flush()
+
+	return r
+}
+
+// isRSTAdornment reports whether a line is an underline, overline or transition: at least three of the same
+// punctuation character.
+func isRSTAdornment(s string) bool {
+	if len(s) < 3 || !unicode.IsPunct(rune(s[0])) && !unicode.IsSymbol(rune(s[0])) || s[0] >= utf8.RuneSelf {
+		return false
+	}
+	return strings.Count(s, s[:1]) == len(s)
+}
+
+// stripRST returns the text of inline reStructuredText, dropping substitutions and keeping the text of references.
+func stripRST(s string) string {
+	s = rstRole.ReplaceAllString(s, "$1")
+	s = rstLiteral.ReplaceAllString(s, "$1")
+	s = rstNamedLink.ReplaceAllString(s, "$1")
+	s = rstInterpreted.ReplaceAllString(s, "$1")
+	s = rstSubstitution.ReplaceAllString(s, "")
+	s = rstReference.ReplaceAllString(s, "$1$2")
+	s = rstEmphasis.ReplaceAllString(s, "")
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -0,0 +1,190 @@
+/*
+ * Copyright 2018-2025 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package labels_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+
+	"github.com/paketo-buildpacks/image-labels/v4/labels"
+)
+
+func testReadme(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+	)
+
+	context("Markdown", func() {
+		it("reads the first heading and paragraph", func() {
+			Expect(labels.ParseReadme("README.md", `# My App
+
+My App serves *fast* answers to
+**every** question.
+
+## Usage
+
+Run it.
+`)).To(Equal(labels.Readme{Title: "My App", Description: "My App serves fast answers to every question."}))
+		})
+
+		it("reads setext headings", func() {
+			Expect(labels.ParseReadme("README.md", "My App\n======\n\nAnswers questions.\n")).
+				To(Equal(labels.Readme{Title: "My App", Description: "Answers questions."}))
+
+			Expect(labels.ParseReadme("README.md", "My App\n------\n\nAnswers questions.\n")).
+				To(Equal(labels.Readme{Title: "My App", Description: "Answers questions."}))
+		})
+
+		it("reads HTML headings", func() {
+			Expect(labels.ParseReadme("README.md", `<h1 align="center">My App</h1>
+<p align="center">
+  <img src="logo.png">
+</p>
+<p align="center">Answers &amp; questions.</p>
+`)).To(Equal(labels.Readme{Title: "My App", Description: "Answers & questions."}))
+		})
+
+		it("removes markup", func() {
+			Expect(labels.ParseReadme("README.md", "# ![logo](logo.png) `my-app` #\n\n"+
+				"A [link](https://example.com) and [reference][ref], <https://example.com>, _emphasis_, ~~struck~~, "+
+				"snake_case, \\*escaped\\* and <br/>tags.\n")).
+				To(Equal(labels.Readme{
+					Title:       "my-app",
+					Description: "A link and reference, https://example.com, emphasis, struck, snake_case, *escaped* and tags.",
+				}))
+		})
+
+		it("skips paragraphs of badges", func() {
+			Expect(labels.ParseReadme("README.md", `# My App
+
+[![Build](https://example.com/build.svg)](https://example.com/build) [![Coverage](https://example.com/coverage.svg)](https://example.com/coverage)
+
+Answers questions.
+`)).To(Equal(labels.Readme{Title: "My App", Description: "Answers questions."}))
+		})
+
+		it("skips text that is not prose", func() {
+			Expect(labels.ParseReadme("README.md", "---\ntitle: Front Matter\n---\n"+
+				"<!--\nA comment\n-->\n"+
+				"# My App\n\n"+
+				"```\ncode\n```\n\n"+
+				"    indented code\n\n"+
+				"* a list\n- another list\n1. an ordered list\n\n"+
+				"| a | table |\n|---|---|\n\n"+
+				"***\n\n"+
+				"[ref]: https://example.com\n\n"+
+				"> [!NOTE]\n> Answers questions.\n")).To(Equal(labels.Readme{Title: "My App", Description: "Answers questions."}))
+		})
+
+		it("reads a paragraph before the first heading", func() {
+			Expect(labels.ParseReadme("README", "Answers questions.\n\n# My App\n")).
+				To(Equal(labels.Readme{Title: "My App", Description: "Answers questions."}))
+		})
+
+		it("returns nothing without a heading or paragraph", func() {
+			Expect(labels.ParseReadme("README.md", "")).To(Equal(labels.Readme{}))
+			Expect(labels.ParseReadme("README.md", "```\ncode\n```\n")).To(Equal(labels.Readme{}))
+		})
+	})
+
+	context("reStructuredText", func() {
+		it("reads the first title and paragraph", func() {
+			Expect(labels.ParseReadme("README.rst", `My App
+======
+
+My App serves *fast* answers to
+**every** question.
+
+Usage
+-----
+
+Run it.
+`)).To(Equal(labels.Readme{Title: "My App", Description: "My App serves fast answers to every question."}))
+		})
+
+		it("reads overlined titles", func() {
+			Expect(labels.ParseReadme("README.rst", "========\n My App\n========\n\nAnswers questions.\n")).
+				To(Equal(labels.Readme{Title: "My App", Description: "Answers questions."}))
+		})
+
+		it("removes markup", func() {
+			Expect(labels.ParseReadme("README.rst", "My App\n======\n\n"+
+				"A `link <https://example.com>`_, a reference_, ``literal``, :code:`role`, |badge| and `interpreted`.\n")).
+				To(Equal(labels.Readme{Title: "My App", Description: "A link, a reference, literal, role, and interpreted."}))
+		})
+
+		it("skips text that is not prose", func() {
+			Expect(labels.ParseReadme("README.rst", `.. image:: https://example.com/build.svg
+   :target: https://example.com/build
+
+.. comment
+
+My App
+======
+
+:Version: 1.2.3
+:License: MIT
+
+- a list
+- another list
+
+Answers questions::
+
+    code
+`)).To(Equal(labels.Readme{Title: "My App", Description: "Answers questions:"}))
+		})
+	})
+
+	context("TruncateDescription", func() {
+		it("does not change a short description", func() {
+			Expect(labels.TruncateDescription("Answers questions.", 18)).To(Equal("Answers questions."))
+			Expect(labels.TruncateDescription("Answers questions.", 0)).To(Equal("Answers questions."))
+		})
+
+		it("cuts a description at the end of a word", func() {
+			Expect(labels.TruncateDescription("Answers every question, quickly.", 26)).To(Equal("Answers every question…"))
+		})
+
+		it("cuts a description with a single long word", func() {
+			Expect(labels.TruncateDescription("Supercalifragilisticexpialidocious", 10)).To(Equal("Supercali…"))
+		})
+
+		it("counts characters rather than bytes", func() {
+			Expect(labels.TruncateDescription("Répond à toutes les questions", 17)).To(Equal("Répond à toutes…"))
+		})
+	})
+
+	context("FindReadme", func() {
+		it("returns the first README found", func() {
+			dir := t.TempDir()
+			writeFiles(t, dir, map[string]string{"README": "", "readme.rst": "", "README.md/index.md": ""})
+
+			name, ok, err := labels.FindReadme(dir)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ok).To(BeTrue())
+			Expect(name).To(Equal("readme.rst"))
+		})
+
+		it("returns nothing without a README", func() {
+			_, ok, err := labels.FindReadme(t.TempDir())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ok).To(BeFalse())
+		})
+	})
+}
@@ -0,0 +1,102 @@
+/*
+ * Copyright 2018-2025 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package labels
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"github.com/buildpacks/libcnb/v2"
+	"github.com/paketo-buildpacks/libpak/v2/log"
+)
+
+// RuntimeLayerName is the name of the launch layer that the labels are written to for the application.
+const RuntimeLayerName = "runtime-labels"
+
+// RuntimeLabelsFile is the name of the file in the runtime layer that the labels are written to.
+const RuntimeLabelsFile = "labels.json"
+
+// RuntimeEnvironmentPrefix is the prefix of the launch environment variables that labels are exported as.
+const RuntimeEnvironmentPrefix = "BPI_LABEL_"
+
+// RuntimeEnvironmentName returns the name of the launch environment variable that a label is exported as. The key
+// is upper cased and every character other than a letter or digit is replaced by '_', so that
+// org.opencontainers.image.revision is exported as BPI_LABEL_ORG_OPENCONTAINERS_IMAGE_REVISION.
+func RuntimeEnvironmentName(key string) string {
+	return RuntimeEnvironmentPrefix + strings.Map(func(r rune) rune {
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			return unicode.ToUpper(r)
+		}
+		return '_'
+	}, key)
+}
+
+// ContributeRuntimeLabels writes the labels as a JSON object of keys to values to a launch layer, and points
+// $BPI_LABELS_FILE at it, so that the application can read the labels of its image at runtime. If env is true, each
+// label is also exported as the launch environment variable named by RuntimeEnvironmentName. When two keys map to
+// the same variable, the first in the order of labels is exported and a warning is logged.
+func ContributeRuntimeLabels(layers libcnb.Layers, labels []libcnb.Label, env bool, logger log.Logger) (libcnb.Layer, error) {
+	layer, err := layers.Layer(RuntimeLayerName)
+	if err != nil {
+		return libcnb.Layer{}, fmt.Errorf("unable to create layer %s\n%w", RuntimeLayerName, err)
+	}
+
+	if layer, err = layer.Reset(); err != nil {
+		return libcnb.Layer{}, fmt.Errorf("unable to reset layer %s\n%w", RuntimeLayerName, err)
+	}
+	layer.LayerTypes = libcnb.LayerTypes{Launch: true}
+
+	m := make(map[string]string, len(labels))
+	for _, l := range labels {
+		m[l.Key] = l.Value
+	}
+
+	b, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return libcnb.Layer{}, fmt.Errorf("unable to encode runtime labels\n%w", err)
+	}
+
+	file := filepath.Join(layer.Path, RuntimeLabelsFile)
+	if err := os.WriteFile(file, append(b, '\n'), 0644); err != nil {
+		return libcnb.Layer{}, fmt.Errorf("unable to write %s\n%w", file, err)
+	}
+	layer.LaunchEnvironment.Default("BPI_LABELS_FILE", file)
+	logger.Bodyf("Writing labels to %s for the application", file)
+
+	if !env {
+		return layer, nil
+	}
+
+	exported := make(map[string]string, len(labels))
+	for _, l := range labels {
+		name := RuntimeEnvironmentName(l.Key)
+		if k, ok := exported[name]; ok {
+			logger.Bodyf("WARNING: Label %s is not exported as $%s, which is already used by label %s", l.Key, name, k)
+			continue
+		}
+
+		exported[name] = l.Key
+		layer.LaunchEnvironment.Default(name, l.Value)
+	}
+	logger.Bodyf("Exporting %d labels as $%s* at launch", len(exported), RuntimeEnvironmentPrefix)
+
+	return layer, nil
+}
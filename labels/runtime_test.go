@@ -0,0 +1,104 @@
+/*
+ * Copyright 2018-2025 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package labels_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/buildpacks/libcnb/v2"
+	. "github.com/onsi/gomega"
+	"github.com/paketo-buildpacks/libpak/v2/log"
+	"github.com/sclevine/spec"
+
+	"github.com/paketo-buildpacks/image-labels/v4/labels"
+)
+
+func testRuntime(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		buf    *bytes.Buffer
+		layers libcnb.Layers
+		logger log.Logger
+	)
+
+	it.Before(func() {
+		buf = &bytes.Buffer{}
+		layers = libcnb.Layers{Path: t.TempDir()}
+		logger = log.NewPaketoLogger(buf)
+	})
+
+	context("RuntimeEnvironmentName", func() {
+		it("upper cases keys and replaces other characters", func() {
+			Expect(labels.RuntimeEnvironmentName("org.opencontainers.image.revision")).To(Equal("BPI_LABEL_ORG_OPENCONTAINERS_IMAGE_REVISION"))
+			Expect(labels.RuntimeEnvironmentName("com.example/team-name")).To(Equal("BPI_LABEL_COM_EXAMPLE_TEAM_NAME"))
+			Expect(labels.RuntimeEnvironmentName("café")).To(Equal("BPI_LABEL_CAF_"))
+		})
+	})
+
+	context("ContributeRuntimeLabels", func() {
+		l := []libcnb.Label{
+			{Key: "com.example.team", Value: "platform"},
+			{Key: "com.example.team-name", Value: "platform team"},
+			{Key: "org.opencontainers.image.revision", Value: "abc123"},
+		}
+
+		it("writes the labels to a launch layer", func() {
+			layer, err := labels.ContributeRuntimeLabels(layers, l, false, logger)
+			Expect(err).NotTo(HaveOccurred())
+
+			file := filepath.Join(layers.Path, "runtime-labels", "labels.json")
+			Expect(layer.Name).To(Equal("runtime-labels"))
+			Expect(layer.LayerTypes).To(Equal(libcnb.LayerTypes{Launch: true}))
+			Expect(layer.LaunchEnvironment).To(Equal(libcnb.Environment{"BPI_LABELS_FILE.default": file}))
+
+			b, err := os.ReadFile(file)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(b).To(MatchJSON(`{
+				"com.example.team": "platform",
+				"com.example.team-name": "platform team",
+				"org.opencontainers.image.revision": "abc123"
+			}`))
+		})
+
+		it("exports the labels as environment variables", func() {
+			layer, err := labels.ContributeRuntimeLabels(layers, l, true, logger)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(layer.LaunchEnvironment).To(Equal(libcnb.Environment{
+				"BPI_LABELS_FILE.default":                             filepath.Join(layers.Path, "runtime-labels", "labels.json"),
+				"BPI_LABEL_COM_EXAMPLE_TEAM.default":                  "platform",
+				"BPI_LABEL_COM_EXAMPLE_TEAM_NAME.default":             "platform team",
+				"BPI_LABEL_ORG_OPENCONTAINERS_IMAGE_REVISION.default": "abc123",
+			}))
+		})
+
+		it("exports the first of labels with the same variable name", func() {
+			layer, err := labels.ContributeRuntimeLabels(layers, []libcnb.Label{
+				{Key: "com.example.team", Value: "platform"},
+				{Key: "com.example_team", Value: "other"},
+			}, true, logger)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(layer.LaunchEnvironment).To(HaveKeyWithValue("BPI_LABEL_COM_EXAMPLE_TEAM.default", "platform"))
+			Expect(buf.String()).To(ContainSubstring("WARNING: Label com.example_team is not exported as $BPI_LABEL_COM_EXAMPLE_TEAM, which is already used by label com.example.team"))
+		})
+	})
+}
@@ -0,0 +1,328 @@
+/*
+ * Copyright 2018-2025 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package labels
+
+import (
+	"strings"
+)
+
+// The $BP_IMAGE_LABELS syntax is read by a single pass state machine over the bytes of the input. In EBNF, where
+// sep is a space, tab, carriage return or newline, it is
+//
+//	labels        = { sep } [ { pair sep { sep } } ( pair | key ) ] { sep }
+//	pair          = key "=" value
+//
+//	key           = quoted-key | bare-key
+//	quoted-key    = ( triple-quoted | double-quoted | single-quoted ) { sep }
+//	bare-key      = bare-key-char { bare-key-char }
+//	bare-key-char = escaped-quote | any byte except '"', "'", "=", tab, carriage return and newline
+//
+//	value         = [ triple-quoted | double-quoted | single-quoted | bare-value ]
+//	bare-value    = bare-char { bare-char }
+//	bare-char     = escaped-quote | any byte except '"', "'" and sep
+//
+//	double-quoted = '"' { escaped-quote | any byte except '"' } '"'
+//	single-quoted = "'" { escaped-quote | any byte except "'" } "'"
+//	triple-quoted = '"""' [ newline ] { escape | any byte } '"""'
+//	              | "'''" [ newline ] { any byte } "'''"
+//
+//	escaped-quote = '\"' | "\'"
+//	escape        = '\\' | '\n' | '\t' | escaped-quote | '\u' hex hex hex hex | '\'
+//
+// Only quotes can be escaped outside of '"""', as in earlier releases, and any other '\' is read as a backslash, so
+// that the \$ understood by Expand passes through. The shortest match applies to triple-quoted keys and values, and a
+// newline directly after the opening quotes is discarded. A bare key may contain spaces, which are kept, and a last key
+// without "=" has an empty value, as in earlier releases.
+
+type scanState int
+
+const (
+	stateKey scanState = iota
+	stateBareKey
+	stateQuotedKey
+	stateTripleQuotedKey
+	stateAfterQuotedKey
+	stateValue
+	stateBareValue
+	stateQuotedValue
+	stateTripleQuotedValue
+	stateAfterQuotedValue
+	stateEnd
+)
+
+type labelPair struct {
+	Key   string
+	Value string
+}
+
+type scanner struct {
+	input string
+	pos   int
+	start int
+	state scanState
+	quote byte
+	key   string
+	b     strings.Builder
+	pairs []labelPair
+}
+
+// scan runs the state machine from the current position until it enters the stop state or reaches the end of the
+// input, returning a *ParseError if the input is invalid.
+func (s *scanner) scan(stop scanState) error {
+	for s.pos < len(s.input) {
+		if err := s.step(s.input[s.pos]); err != nil {
+			return err
+		}
+
+		if s.state == stop {
+			return nil
+		}
+	}
+
+	return s.end()
+}
+
+func (s *scanner) step(c byte) error {
+	switch s.state {
+	case stateKey:
+		if !isSeparator(rune(c)) {
+			s.start = s.pos
+			s.key = ""
+		}
+
+		switch {
+		case isSeparator(rune(c)):
+			s.pos++
+		case s.tripleQuote():
+			s.state = stateTripleQuotedKey
+		case c == '"' || c == '\'':
+			s.quote = c
+			s.pos++
+			s.state = stateQuotedKey
+		case c == '=':
+			return s.fail(s.pos, EmptyKey, nil)
+		default:
+			s.state = stateBareKey
+		}
+
+	case stateBareKey:
+		switch {
+		case c == '=':
+			s.pos++
+			s.startValue()
+		case (c == '\t' || c == '\r' || c == '\n') && strings.TrimLeft(s.input[s.pos:], " \t\r\n") == "":
+			s.pos = len(s.input)
+		case c == '\t' || c == '\r' || c == '\n':
+			s.key = s.b.String()
+			return s.fail(s.pos, MissingEquals, ErrMissingEquals)
+		case c == '"' || c == '\'':
+			return s.fail(s.pos, UnexpectedQuote, ErrUnexpectedQuote)
+		default:
+			s.bare(c)
+		}
+
+	case stateQuotedKey, stateQuotedValue:
+		if c == s.quote {
+			s.pos++
+			if s.state == stateQuotedKey {
+				s.state = stateAfterQuotedKey
+			} else {
+				s.emit()
+				s.state = stateAfterQuotedValue
+			}
+			return nil
+		}
+		s.bare(c)
+
+	case stateAfterQuotedKey:
+		switch {
+		case isSeparator(rune(c)):
+			s.pos++
+		case c == '=':
+			if s.b.Len() == 0 {
+				return s.fail(s.pos, EmptyKey, nil)
+			}
+			s.pos++
+			s.startValue()
+		default:
+			return s.fail(s.pos, CharactersAfterQuote, ErrCharactersAfterQuote)
+		}
+
+	case stateValue:
+		switch {
+		case isSeparator(rune(c)):
+			s.pos++
+			s.emit()
+			s.state = stateKey
+		case s.tripleQuote():
+			s.state = stateTripleQuotedValue
+		case c == '"' || c == '\'':
+			s.quote = c
+			s.pos++
+			s.state = stateQuotedValue
+		default:
+			s.state = stateBareValue
+		}
+
+	case stateBareValue:
+		switch {
+		case isSeparator(rune(c)):
+			s.pos++
+			s.emit()
+			s.state = stateKey
+		case c == '"' || c == '\'':
+			return s.fail(s.pos, UnexpectedQuote, ErrUnexpectedQuote)
+		default:
+			s.bare(c)
+		}
+
+	case stateTripleQuotedKey, stateTripleQuotedValue:
+		if strings.HasPrefix(s.input[s.pos:], strings.Repeat(string(s.quote), 3)) {
+			s.pos += 3
+			if s.state == stateTripleQuotedKey {
+				s.state = stateAfterQuotedKey
+			} else {
+				s.emit()
+				s.state = stateAfterQuotedValue
+			}
+			return nil
+		}
+		return s.tripleQuoted(c)
+
+	case stateAfterQuotedValue:
+		if !isSeparator(rune(c)) {
+			return s.fail(s.pos, CharactersAfterQuote, ErrCharactersAfterQuote)
+		}
+		s.pos++
+		s.state = stateKey
+	}
+
+	return nil
+}
+
+// bare reads a byte of a key or value that is not triple-quoted, in which only quotes can be escaped.
+func (s *scanner) bare(c byte) {
+	if c == '\\' && s.pos+1 < len(s.input) && (s.input[s.pos+1] == '"' || s.input[s.pos+1] == '\'') {
+		s.b.WriteByte(s.input[s.pos+1])
+		s.pos += 2
+		return
+	}
+
+	s.b.WriteByte(c)
+	s.pos++
+}
+
+// tripleQuote starts a key or value quoted with three double or three single quotes, if there is one at the current
+// position, discarding a newline directly after the quotes.
+func (s *scanner) tripleQuote() bool {
+	if !strings.HasPrefix(s.input[s.pos:], `"""`) && !strings.HasPrefix(s.input[s.pos:], `'''`) {
+		return false
+	}
+
+	s.quote = s.input[s.pos]
+	s.pos += 3
+	if strings.HasPrefix(s.input[s.pos:], "\r\n") {
+		s.pos += 2
+	} else if strings.HasPrefix(s.input[s.pos:], "\n") {
+		s.pos++
+	}
+
+	return true
+}
+
+// tripleQuoted reads a byte that is not the closing quotes of a triple-quoted key or value. Three double quotes
+// support every escape and three single quotes none.
+func (s *scanner) tripleQuoted(c byte) error {
+	if c != '\\' || s.quote == '\'' {
+		s.b.WriteByte(c)
+		s.pos++
+		return nil
+	}
+
+	v, n, err := readEscape(s.input, s.pos)
+	if err != nil {
+		return s.fail(s.pos, InvalidEscape, err)
+	}
+	s.b.WriteString(v)
+	s.pos += n
+
+	return nil
+}
+
+func (s *scanner) startValue() {
+	s.key = s.b.String()
+	s.b.Reset()
+	s.state = stateValue
+}
+
+func (s *scanner) emit() {
+	s.pairs = append(s.pairs, labelPair{Key: s.key, Value: s.b.String()})
+	s.b.Reset()
+}
+
+// end completes the scan at the end of the input.
+func (s *scanner) end() error {
+	switch s.state {
+	case stateAfterQuotedKey:
+		if s.b.Len() == 0 {
+			return s.fail(len(s.input), EmptyKey, nil)
+		}
+		fallthrough
+	case stateBareKey:
+		// a key without an '=' at the end of the input has an empty value, as in earlier releases
+		s.startValue()
+		s.emit()
+	case stateQuotedKey, stateTripleQuotedKey, stateQuotedValue, stateTripleQuotedValue:
+		return s.fail(len(s.input), UnterminatedQuote, ErrUnterminatedQuote)
+	case stateValue, stateBareValue:
+		s.emit()
+	}
+
+	s.state = stateEnd
+	return nil
+}
+
+func (s *scanner) fail(offset int, kind ParseErrorKind, err error) error {
+	return newParseError(s.input, offset, s.reportedEnd(offset, kind), s.key, kind, err)
+}
+
+// reportedEnd returns the offset at which earlier releases reported that the key or value being read ended, so that
+// error messages stay the same. A key ended at the next '=', or the last character, and a value at the space after
+// the characters following its closing quote, or the end of the input. Failures that earlier releases did not
+// detect are reported where they occur.
+func (s *scanner) reportedEnd(offset int, kind ParseErrorKind) int {
+	switch {
+	case kind == MissingEquals || kind == InvalidEscape:
+		return offset
+	case s.key == "":
+		if i := strings.IndexByte(s.input[s.start:], '='); i >= 0 {
+			return s.start + i
+		}
+		return len(s.input) - 1
+	case kind == CharactersAfterQuote:
+		if i := strings.IndexByte(s.input[offset:], ' '); i >= 0 && offset+i+1 < len(s.input) {
+			return offset + i
+		}
+		return len(s.input)
+	default:
+		return len(s.input)
+	}
+}
+
+func isSeparator(ch rune) bool {
+	return ch == ' ' || ch == '\t' || ch == '\r' || ch == '\n'
+}
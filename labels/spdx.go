@@ -0,0 +1,297 @@
+/*
+ * Copyright 2018-2025 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package labels
+
+import (
+	_ "embed"
+	"fmt"
+	"strings"
+)
+
+//go:embed spdx/licenses.txt
+var spdxLicenseList string
+
+//go:embed spdx/exceptions.txt
+var spdxExceptionList string
+
+var (
+	spdxLicenses   = readSPDXList(spdxLicenseList)
+	spdxExceptions = readSPDXList(spdxExceptionList)
+)
+
+// readSPDXList returns the identifiers in a list keyed by their lower case form, as SPDX identifiers are matched
+// case-insensitively.
+func readSPDXList(s string) map[string]string {
+	m := make(map[string]string)
+	for _, line := range strings.Split(s, "\n") {
+		if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "#") {
+			m[strings.ToLower(line)] = line
+		}
+	}
+	return m
+}
+
+type spdxToken struct {
+	value  string
+	offset int
+}
+
+// ValidateLicenseExpression checks that a string is a valid SPDX license expression as defined in Annex D of the SPDX
+// specification, using the embedded list of SPDX license and exception identifiers.
+//
+//	compound = simple | compound "WITH" exception | compound "AND" compound | compound "OR" compound | "(" compound ")"
+//	simple   = license-id | license-id "+" | "LicenseRef-" idstring | "DocumentRef-" idstring ":LicenseRef-" idstring
+//
+// WITH binds tighter than AND, which binds tighter than OR. The values NONE and NOASSERTION are also accepted.
+func ValidateLicenseExpression(s string) error {
+	if t := strings.TrimSpace(s); t == "NONE" || t == "NOASSERTION" {
+		return nil
+	}
+
+	p := &spdxParser{tokens: tokenizeSPDX(s)}
+	if len(p.tokens) == 0 {
+		return fmt.Errorf("license expression must not be empty")
+	}
+
+	if err := p.parseOr(); err != nil {
+		return err
+	}
+
+	if t, ok := p.peek(); ok {
+		return fmt.Errorf("unexpected %q at offset %d", t.value, t.offset)
+	}
+
+	return nil
+}
+
+func tokenizeSPDX(s string) []spdxToken {
+	var (
+		tokens []spdxToken
+		start  = -1
+	)
+
+	for i := 0; i <= len(s); i++ {
+		if i < len(s) && s[i] != ' ' && s[i] != '\t' && s[i] != '\n' && s[i] != '(' && s[i] != ')' {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+
+		if start >= 0 {
+			tokens = append(tokens, spdxToken{value: s[start:i], offset: start})
+			start = -1
+		}
+
+		if i < len(s) && (s[i] == '(' || s[i] == ')') {
+			tokens = append(tokens, spdxToken{value: s[i : i+1], offset: i})
+		}
+	}
+
+	return tokens
+}
+
+type spdxParser struct {
+	tokens []spdxToken
+	pos    int
+}
+
+func (p *spdxParser) peek() (spdxToken, bool) {
+	if p.pos >= len(p.tokens) {
+		return spdxToken{}, false
+	}
+	return p.tokens[p.pos], true
+}
+
+func (p *spdxParser) next() (spdxToken, error) {
+	t, ok := p.peek()
+	if !ok {
+		return spdxToken{}, fmt.Errorf("unexpected end of license expression")
+	}
+	p.pos++
+	return t, nil
+}
+
+// operator reports whether the next token is an operator, returning an error if it is one in the wrong case.
+func (p *spdxParser) operator(op string) (bool, error) {
+	t, ok := p.peek()
+	if !ok {
+		return false, nil
+	}
+
+	if t.value == op {
+		p.pos++
+		return true, nil
+	}
+
+	if strings.EqualFold(t.value, op) {
+		return false, fmt.Errorf("operator %q at offset %d must be upper case %s", t.value, t.offset, op)
+	}
+
+	return false, nil
+}
+
+func (p *spdxParser) parseOr() error {
+	if err := p.parseAnd(); err != nil {
+		return err
+	}
+
+	for {
+		ok, err := p.operator("OR")
+		if err != nil || !ok {
+			return err
+		}
+
+		if err := p.parseAnd(); err != nil {
+			return err
+		}
+	}
+}
+
+func (p *spdxParser) parseAnd() error {
+	if err := p.parseWith(); err != nil {
+		return err
+	}
+
+	for {
+		ok, err := p.operator("AND")
+		if err != nil || !ok {
+			return err
+		}
+
+		if err := p.parseWith(); err != nil {
+			return err
+		}
+	}
+}
+
+func (p *spdxParser) parseWith() error {
+	isLicense, err := p.parsePrimary()
+	if err != nil {
+		return err
+	}
+
+	ok, err := p.operator("WITH")
+	if err != nil || !ok {
+		return err
+	}
+
+	if !isLicense {
+		t := p.tokens[p.pos-1]
+		return fmt.Errorf("WITH at offset %d must follow a license identifier", t.offset)
+	}
+
+	t, err := p.next()
+	if err != nil {
+		return err
+	}
+
+	if _, ok := spdxExceptions[strings.ToLower(t.value)]; !ok {
+		return fmt.Errorf("unknown license exception %q at offset %d", t.value, t.offset)
+	}
+
+	return nil
+}
+
+// parsePrimary parses a parenthesized expression or simple license expression, reporting which it was.
+func (p *spdxParser) parsePrimary() (bool, error) {
+	t, err := p.next()
+	if err != nil {
+		return false, err
+	}
+
+	switch t.value {
+	case "(":
+		if err := p.parseOr(); err != nil {
+			return false, err
+		}
+
+		c, err := p.next()
+		if err != nil {
+			return false, fmt.Errorf("unable to find closing parenthesis for offset %d", t.offset)
+		}
+		if c.value != ")" {
+			return false, fmt.Errorf("unexpected %q at offset %d", c.value, c.offset)
+		}
+		return false, nil
+
+	case ")", "AND", "OR", "WITH":
+		return false, fmt.Errorf("unexpected %q at offset %d", t.value, t.offset)
+	}
+
+	return true, validateSimpleLicense(t)
+}
+
+func validateSimpleLicense(t spdxToken) error {
+	id := strings.TrimSuffix(t.value, "+")
+
+	if ref, ok := strings.CutPrefix(id, "DocumentRef-"); ok {
+		doc, license, ok := strings.Cut(ref, ":")
+		if !ok || !isSPDXIDString(doc) {
+			return fmt.Errorf("invalid document reference %q at offset %d", t.value, t.offset)
+		}
+		id = license
+	}
+
+	if ref, ok := strings.CutPrefix(id, "LicenseRef-"); ok {
+		if !isSPDXIDString(ref) {
+			return fmt.Errorf("invalid license reference %q at offset %d", t.value, t.offset)
+		}
+		return nil
+	}
+
+	if _, ok := spdxLicenses[strings.ToLower(id)]; ok {
+		return nil
+	}
+
+	if s, ok := suggestLicense(id); ok {
+		return fmt.Errorf("unknown license identifier %q at offset %d, did you mean %s?", t.value, t.offset, s)
+	}
+	return fmt.Errorf("unknown license identifier %q at offset %d", t.value, t.offset)
+}
+
+func isSPDXIDString(s string) bool {
+	if s == "" {
+		return false
+	}
+
+	for _, c := range s {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '.') {
+			return false
+		}
+	}
+	return true
+}
+
+// suggestLicense returns the license identifier closest to an unknown one, if it is close enough to be a likely typo.
+// Punctuation is ignored when comparing, so that Apache2 is close to Apache-2.0.
+func suggestLicense(id string) (string, bool) {
+	normalize := strings.NewReplacer("-", "", ".", "", "+", "")
+
+	var (
+		best     string
+		distance = -1
+	)
+
+	for k, v := range spdxLicenses {
+		if d := levenshtein(normalize.Replace(strings.ToLower(id)), normalize.Replace(k)); distance < 0 || d < distance || d == distance && v < best {
+			best, distance = v, d
+		}
+	}
+
+	return best, distance >= 0 && distance <= 2
+}
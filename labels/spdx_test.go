@@ -0,0 +1,93 @@
+/*
+ * Copyright 2018-2025 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package labels_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+
+	"github.com/paketo-buildpacks/image-labels/v4/labels"
+)
+
+func testSPDX(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+	)
+
+	it("accepts valid expressions", func() {
+		for _, s := range []string{
+			"MIT",
+			"mit",
+			"Apache-2.0",
+			"GPL-2.0+",
+			"GPL-2.0-or-later WITH Classpath-exception-2.0",
+			"MIT OR Apache-2.0",
+			"MIT AND (LGPL-2.1-or-later OR BSD-3-Clause)",
+			"((MIT))",
+			"(MIT OR Apache-2.0) AND Zlib",
+			"Apache-2.0 WITH LLVM-exception OR MIT",
+			"LicenseRef-Proprietary",
+			"DocumentRef-spdx-tool-1.2:LicenseRef-MIT-Style-2",
+			"NONE",
+			"NOASSERTION",
+			" MIT ",
+		} {
+			Expect(labels.ValidateLicenseExpression(s)).To(Succeed(), s)
+		}
+	})
+
+	it("rejects empty expressions", func() {
+		Expect(labels.ValidateLicenseExpression("  ")).To(MatchError("license expression must not be empty"))
+	})
+
+	it("rejects unknown identifiers", func() {
+		Expect(labels.ValidateLicenseExpression("MIT OR Apache2")).
+			To(MatchError(`unknown license identifier "Apache2" at offset 7, did you mean Apache-2.0?`))
+		Expect(labels.ValidateLicenseExpression("Proprietary")).
+			To(MatchError(`unknown license identifier "Proprietary" at offset 0`))
+		Expect(labels.ValidateLicenseExpression("LicenseRef-")).
+			To(MatchError(`invalid license reference "LicenseRef-" at offset 0`))
+		Expect(labels.ValidateLicenseExpression("DocumentRef-x")).
+			To(MatchError(`invalid document reference "DocumentRef-x" at offset 0`))
+	})
+
+	it("rejects unknown exceptions", func() {
+		Expect(labels.ValidateLicenseExpression("GPL-2.0-only WITH Foo-exception")).
+			To(MatchError(`unknown license exception "Foo-exception" at offset 18`))
+		Expect(labels.ValidateLicenseExpression("(MIT OR GPL-2.0-only) WITH Classpath-exception-2.0")).
+			To(MatchError("WITH at offset 22 must follow a license identifier"))
+		Expect(labels.ValidateLicenseExpression("GPL-2.0-only WITH")).
+			To(MatchError("unexpected end of license expression"))
+	})
+
+	it("rejects malformed expressions", func() {
+		Expect(labels.ValidateLicenseExpression("MIT Apache-2.0")).
+			To(MatchError(`unexpected "Apache-2.0" at offset 4`))
+		Expect(labels.ValidateLicenseExpression("MIT OR")).
+			To(MatchError("unexpected end of license expression"))
+		Expect(labels.ValidateLicenseExpression("OR MIT")).
+			To(MatchError(`unexpected "OR" at offset 0`))
+		Expect(labels.ValidateLicenseExpression("(MIT")).
+			To(MatchError("unable to find closing parenthesis for offset 0"))
+		Expect(labels.ValidateLicenseExpression("MIT)")).
+			To(MatchError(`unexpected ")" at offset 3`))
+		Expect(labels.ValidateLicenseExpression("MIT or Apache-2.0")).
+			To(MatchError(`operator "or" at offset 4 must be upper case OR`))
+	})
+}
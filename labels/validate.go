@@ -0,0 +1,282 @@
+/*
+ * Copyright 2018-2025 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package labels
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/buildpacks/libcnb/v2"
+	"github.com/paketo-buildpacks/libpak/v2/log"
+)
+
+// ValidateKey checks a label key against the OCI annotation rules, returning a description of each problem found.
+//
+// A key must be non-empty and must not contain whitespace, control characters or '='. It should follow the
+// reverse-DNS convention: two or more '.' separated components of letters, digits, '_' and '-', where a component
+// does not begin or end with '-'. Keys close to, but not the same as, one of the org.opencontainers.image.* keys are
+// reported with a suggested correction.
+func ValidateKey(key string) []string {
+	if key == "" {
+		return []string{"key must not be empty"}
+	}
+
+	if isKnownKey(key) {
+		return nil
+	}
+
+	var problems []string
+
+	for _, c := range key {
+		if unicode.IsSpace(c) || unicode.IsControl(c) || c == '=' {
+			problems = append(problems, fmt.Sprintf("key must not contain %q", c))
+			break
+		}
+	}
+
+	if !isReverseDNS(key) {
+		problems = append(problems, "key should use reverse-DNS notation such as com.example.key, with letters, digits, '_', '-' and '.'")
+	}
+
+	if s, ok := suggestKey(key); ok {
+		problems = append(problems, fmt.Sprintf("key is not a pre-defined OCI key, did you mean %s?", s))
+	}
+
+	return problems
+}
+
+func isReverseDNS(key string) bool {
+	parts := strings.Split(key, ".")
+	if len(parts) < 2 {
+		return false
+	}
+
+	for _, p := range parts {
+		if p == "" || p[0] == '-' || p[len(p)-1] == '-' {
+			return false
+		}
+
+		for _, c := range p {
+			if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '-') {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// OtherOCIKeys are pre-defined OCI annotation keys that are not set by a $BP_OCI_* variable.
+var OtherOCIKeys = []string{
+	"org.opencontainers.artifact.created",
+	"org.opencontainers.artifact.description",
+	"org.opencontainers.image.base.digest",
+	"org.opencontainers.image.base.name",
+	"org.opencontainers.referrers.filtersApplied",
+}
+
+func knownKeys() []string {
+	known := append([]string{}, OtherOCIKeys...)
+	for _, v := range Labels {
+		known = append(known, v)
+	}
+	sort.Strings(known)
+	return known
+}
+
+func isKnownKey(key string) bool {
+	for _, k := range knownKeys() {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+// suggestKey returns the pre-defined OCI key closest to a key that is either in the org.opencontainers.image
+// namespace or within a small edit distance of a pre-defined key, but is not itself pre-defined.
+func suggestKey(key string) (string, bool) {
+	var (
+		best     string
+		distance = -1
+	)
+	for _, k := range knownKeys() {
+		if d := levenshtein(strings.ToLower(key), k); distance < 0 || d < distance {
+			best, distance = k, d
+		}
+	}
+
+	if distance <= 3 || strings.HasPrefix(strings.ToLower(key), "org.opencontainers.image.") {
+		return best, true
+	}
+
+	return "", false
+}
+
+func levenshtein(a string, b string) int {
+	ra, rb := []rune(a), []rune(b)
+
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(rb)]
+}
+
+// ValueValidators check the values of pre-defined OCI keys, returning a description of each problem found.
+var ValueValidators = map[string]func(string) []string{
+	"org.opencontainers.image.created":       validateCreated,
+	"org.opencontainers.image.documentation": validateURL,
+	"org.opencontainers.image.licenses":      validateLicenses,
+	"org.opencontainers.image.ref.name":      validateRefName,
+	"org.opencontainers.image.source":        validateSource,
+	"org.opencontainers.image.url":           validateURL,
+}
+
+// ValueAdvisories check the values of pre-defined OCI keys against conventions that are often, but not always,
+// followed, returning a description of each departure found. Unlike the problems found by ValueValidators, these are
+// only logged and never fail the build.
+var ValueAdvisories = map[string]func(string) []string{
+	"org.opencontainers.image.version": adviseVersion,
+}
+
+// AdviseValue checks the value of a label against the conventions for its key, returning a description of each
+// departure found.
+func AdviseValue(key string, value string) []string {
+	if f, ok := ValueAdvisories[key]; ok {
+		return f(value)
+	}
+	return nil
+}
+
+// ValidateValue checks the value of a label against the format defined for its key, returning a description of each
+// problem found. Values of keys without a defined format are always valid.
+func ValidateValue(key string, value string) []string {
+	if f, ok := ValueValidators[key]; ok {
+		return f(value)
+	}
+	return nil
+}
+
+func validateCreated(value string) []string {
+	if _, err := time.Parse(time.RFC3339Nano, value); err != nil {
+		return []string{fmt.Sprintf("value %q is not an RFC 3339 date-time such as 2006-01-02T15:04:05Z", value)}
+	}
+	return nil
+}
+
+func validateLicenses(value string) []string {
+	if err := ValidateLicenseExpression(value); err != nil {
+		return []string{fmt.Sprintf("value %q is not a valid SPDX license expression: %s", value, err)}
+	}
+	return nil
+}
+
+func validateURL(value string) []string {
+	u, err := url.Parse(value)
+	if err != nil {
+		return []string{fmt.Sprintf("value %q is not a URL: %s", value, err)}
+	}
+
+	if u.Scheme == "" || u.Host == "" && u.Opaque == "" {
+		return []string{fmt.Sprintf("value %q is not an absolute URL with a scheme and host", value)}
+	}
+
+	return nil
+}
+
+// scpAddress matches scp-like git addresses such as git@github.com:example/app.git.
+var scpAddress = regexp.MustCompile(`^[A-Za-z0-9._-]+@[A-Za-z0-9.-]+:[^/].*$`)
+
+func validateSource(value string) []string {
+	if scpAddress.MatchString(value) {
+		return nil
+	}
+	return validateURL(value)
+}
+
+// refName is the grammar for org.opencontainers.image.ref.name defined by the OCI image specification.
+var refName = regexp.MustCompile(`^[A-Za-z0-9]+(?:(?:[-._:@+]|--)[A-Za-z0-9]+)*(?:/[A-Za-z0-9]+(?:(?:[-._:@+]|--)[A-Za-z0-9]+)*)*$`)
+
+func validateRefName(value string) []string {
+	if !refName.MatchString(value) {
+		return []string{fmt.Sprintf("value %q does not match the OCI ref name grammar of alphanumeric components separated by '-', '.', '_', ':', '@', '+', '--' or '/'", value)}
+	}
+	return nil
+}
+
+// semanticVersion is the regular expression for Semantic Versioning 2.0.0, allowing an optional leading v.
+var semanticVersion = regexp.MustCompile(`^v?(0|[1-9]\d*)\.(0|[1-9]\d*)\.(0|[1-9]\d*)(?:-((?:0|[1-9]\d*|\d*[a-zA-Z-][0-9a-zA-Z-]*)(?:\.(?:0|[1-9]\d*|\d*[a-zA-Z-][0-9a-zA-Z-]*))*))?(?:\+([0-9a-zA-Z-]+(?:\.[0-9a-zA-Z-]+)*))?$`)
+
+func adviseVersion(value string) []string {
+	if !semanticVersion.MatchString(value) {
+		return []string{fmt.Sprintf("value %q is not a semantic version such as 1.2.3", value)}
+	}
+	return nil
+}
+
+// validateLabels logs a warning for each problem with the labels, returning an error listing all of them if strict
+// is set. Departures from the conventions of ValueAdvisories are always only logged.
+func validateLabels(labels []libcnb.Label, strict bool, logger log.Logger) error {
+	var problems []string
+
+	for _, l := range labels {
+		for _, p := range AdviseValue(l.Key, l.Value) {
+			logger.Bodyf("WARNING: %s: %s", l.Key, p)
+		}
+
+		for _, p := range ValidateKey(l.Key) {
+			problems = append(problems, fmt.Sprintf("%s: %s", l.Key, p))
+		}
+		for _, p := range ValidateValue(l.Key, l.Value) {
+			problems = append(problems, fmt.Sprintf("%s: %s", l.Key, p))
+		}
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+
+	if strict {
+		return fmt.Errorf("invalid labels with $BP_IMAGE_LABELS_STRICT set\n%s", strings.Join(problems, "\n"))
+	}
+
+	for _, p := range problems {
+		logger.Bodyf("WARNING: %s", p)
+	}
+
+	return nil
+}
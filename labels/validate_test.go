@@ -0,0 +1,157 @@
+/*
+ * Copyright 2018-2025 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package labels_test
+
+import (
+	"fmt"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+
+	"github.com/paketo-buildpacks/image-labels/v4/labels"
+)
+
+func testValidate(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+	)
+
+	context("ValidateKey", func() {
+		it("accepts pre-defined keys", func() {
+			for _, v := range labels.Labels {
+				Expect(labels.ValidateKey(v)).To(BeEmpty())
+			}
+			for _, v := range labels.OtherOCIKeys {
+				Expect(labels.ValidateKey(v)).To(BeEmpty())
+			}
+		})
+
+		it("accepts reverse-DNS keys", func() {
+			Expect(labels.ValidateKey("com.example.team")).To(BeEmpty())
+			Expect(labels.ValidateKey("io.buildpacks.build-id2.name")).To(BeEmpty())
+			Expect(labels.ValidateKey("com.example.myKey")).To(BeEmpty())
+			Expect(labels.ValidateKey("com.example.build_id")).To(BeEmpty())
+			Expect(labels.ValidateKey("com.example.team2")).To(BeEmpty())
+			Expect(labels.ValidateKey("com.xn--bcher-kva.key")).To(BeEmpty())
+		})
+
+		it("rejects an empty key", func() {
+			Expect(labels.ValidateKey("")).To(Equal([]string{"key must not be empty"}))
+		})
+
+		it("rejects whitespace and equals signs", func() {
+			Expect(labels.ValidateKey("com.example.my key")).To(ContainElement(`key must not contain ' '`))
+			Expect(labels.ValidateKey("com.example.a=b")).To(ContainElement(`key must not contain '='`))
+			Expect(labels.ValidateKey("com.example.a\nb")).To(ContainElement(`key must not contain '\n'`))
+		})
+
+		it("warns on keys that are not reverse-DNS", func() {
+			for _, k := range []string{"alpha", "com..example", "com.example.", "-com.example", "com.example-.team", "com.example/team"} {
+				Expect(labels.ValidateKey(k)).To(ContainElement(ContainSubstring("key should use reverse-DNS notation")), k)
+			}
+		})
+
+		it("suggests corrections for misspelled OCI keys", func() {
+			Expect(labels.ValidateKey("org.opencontainers.image.revison")).To(Equal([]string{
+				"key is not a pre-defined OCI key, did you mean org.opencontainers.image.revision?",
+			}))
+			Expect(labels.ValidateKey("org.opencontainer.image.title")).To(Equal([]string{
+				"key is not a pre-defined OCI key, did you mean org.opencontainers.image.title?",
+			}))
+			Expect(labels.ValidateKey("Org.OpenContainers.Image.Title")).To(ContainElement(
+				"key is not a pre-defined OCI key, did you mean org.opencontainers.image.title?",
+			))
+			Expect(labels.ValidateKey("org.opencontainers.image.commit")).To(ContainElement(
+				ContainSubstring("key is not a pre-defined OCI key, did you mean"),
+			))
+		})
+
+		it("does not suggest corrections for unrelated keys", func() {
+			Expect(labels.ValidateKey("com.example.revison")).To(BeEmpty())
+		})
+	})
+	context("ValidateValue", func() {
+		it("accepts values of keys without a format", func() {
+			Expect(labels.ValidateValue("org.opencontainers.image.title", "anything at all")).To(BeEmpty())
+			Expect(labels.ValidateValue("com.example.team", "")).To(BeEmpty())
+		})
+
+		it("validates created", func() {
+			Expect(labels.ValidateValue("org.opencontainers.image.created", "2025-01-02T03:04:05Z")).To(BeEmpty())
+			Expect(labels.ValidateValue("org.opencontainers.image.created", "2025-01-02T03:04:05.123+01:00")).To(BeEmpty())
+			Expect(labels.ValidateValue("org.opencontainers.image.created", "2025-01-02")).To(Equal([]string{
+				`value "2025-01-02" is not an RFC 3339 date-time such as 2006-01-02T15:04:05Z`,
+			}))
+		})
+
+		it("validates licenses", func() {
+			Expect(labels.ValidateValue("org.opencontainers.image.licenses", "MIT OR Apache-2.0")).To(BeEmpty())
+			Expect(labels.ValidateValue("org.opencontainers.image.licenses", "Apache 2")).To(Equal([]string{
+				`value "Apache 2" is not a valid SPDX license expression: unknown license identifier "Apache" at offset 0, did you mean Apache-1.0?`,
+			}))
+		})
+
+		it("validates URLs", func() {
+			for _, k := range []string{"org.opencontainers.image.url", "org.opencontainers.image.documentation", "org.opencontainers.image.source"} {
+				Expect(labels.ValidateValue(k, "https://example.com/docs")).To(BeEmpty())
+				Expect(labels.ValidateValue(k, "example.com/docs")).To(Equal([]string{
+					`value "example.com/docs" is not an absolute URL with a scheme and host`,
+				}))
+			}
+
+			Expect(labels.ValidateValue("org.opencontainers.image.url", "http://[::1")).To(ConsistOf(ContainSubstring("is not a URL")))
+		})
+
+		it("accepts scp-like git addresses as source", func() {
+			Expect(labels.ValidateValue("org.opencontainers.image.source", "git@github.com:example/app.git")).To(BeEmpty())
+			Expect(labels.ValidateValue("org.opencontainers.image.url", "git@github.com:example/app.git")).NotTo(BeEmpty())
+		})
+
+		it("validates ref names", func() {
+			for _, v := range []string{"main", "v1.2.3", "feature/my-branch", "release--1", "a_b:c@d+e"} {
+				Expect(labels.ValidateValue("org.opencontainers.image.ref.name", v)).To(BeEmpty(), v)
+			}
+			for _, v := range []string{"", "-main", "main-", "feature//x", "my branch", "a---b"} {
+				Expect(labels.ValidateValue("org.opencontainers.image.ref.name", v)).To(ConsistOf(ContainSubstring("does not match the OCI ref name grammar")), v)
+			}
+		})
+
+		it("accepts any version", func() {
+			for _, v := range []string{"1.2.3", "1.0-SNAPSHOT", "2024.10", "latest"} {
+				Expect(labels.ValidateValue("org.opencontainers.image.version", v)).To(BeEmpty(), v)
+			}
+		})
+	})
+
+	context("AdviseValue", func() {
+		it("accepts values of keys without a convention", func() {
+			Expect(labels.AdviseValue("org.opencontainers.image.title", "anything at all")).To(BeEmpty())
+		})
+
+		it("advises semantic versions", func() {
+			for _, v := range []string{"1.2.3", "v1.2.3", "1.0.0-rc.1+build.5", "0.0.0"} {
+				Expect(labels.AdviseValue("org.opencontainers.image.version", v)).To(BeEmpty(), v)
+			}
+			for _, v := range []string{"1.2", "01.2.3", "latest", "1.2.3-", "1.0-SNAPSHOT"} {
+				Expect(labels.AdviseValue("org.opencontainers.image.version", v)).To(Equal([]string{
+					fmt.Sprintf("value %q is not a semantic version such as 1.2.3", v),
+				}), v)
+			}
+		})
+	})
+}